@@ -0,0 +1,211 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Severity ranks the findings of Audit
+type Severity int
+
+// The severities of findings
+const (
+	// SeverityInfo is worth knowing but harmless, such as a missing directory
+	SeverityInfo Severity = iota
+	// SeverityWarning lets another user tamper with files the application can recreate, such as its cache
+	SeverityWarning
+	// SeverityCritical lets another user change the application's config or data
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// Problem is what Audit found wrong with a path
+type Problem int
+
+// The problems Audit looks for
+const (
+	// ProblemMissing is a directory that does not exist
+	ProblemMissing Problem = iota
+	// ProblemWritable is a file or directory other users can write to
+	ProblemWritable
+	// ProblemOwner is a file or directory owned by another user than the user or root
+	ProblemOwner
+	// ProblemSymlink is a symbolic link pointing outside of the directory being audited
+	ProblemSymlink
+)
+
+func (p Problem) String() string {
+	switch p {
+	case ProblemMissing:
+		return "missing"
+	case ProblemWritable:
+		return "writable by others"
+	case ProblemOwner:
+		return "owned by another user"
+	case ProblemSymlink:
+		return "symbolic link out of the directory"
+	}
+	return "unknown"
+}
+
+// Finding is a problem Audit found with a path
+type Finding struct {
+	Kind     Kind
+	Path     string
+	Problem  Problem
+	Severity Severity
+	// Detail tells more about the problem, such as the permissions or the target of a link
+	Detail string
+}
+
+func (f Finding) String() string {
+	s := f.Severity.String() + ": " + f.Path + " is " + f.Problem.String()
+	if f.Detail != "" {
+		s += " (" + f.Detail + ")"
+	}
+	return s
+}
+
+// Audit checks the application's directories of every kind and everything in them for paths another
+// user could use to change what the application reads: files and directories writable by others or owned
+// by another user than the user or root, and symbolic links out of the directory. The directories holding
+// them, up to the root, are checked too. Missing directories are reported with SeverityInfo.
+// Permissions are not checked on Windows and Plan 9.
+func (x *XDG) Audit() []Finding {
+	a := &auditor{seen: make(map[string]bool)}
+	for _, kind := range []Kind{Data, Config, Cache, State, Runtime} {
+		for _, dir := range x.SearchDirs(kind) {
+			a.auditDir(kind, dir)
+		}
+	}
+	return a.findings
+}
+
+type auditor struct {
+	findings []Finding
+	seen     map[string]bool
+}
+
+// auditSeverity is the severity of another user being able to change files of kind
+func auditSeverity(kind Kind) Severity {
+	if kind == Config || kind == Data {
+		return SeverityCritical
+	}
+	return SeverityWarning
+}
+
+func (a *auditor) auditDir(kind Kind, dir string) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		a.findings = append(a.findings, Finding{Kind: kind, Path: dir, Problem: ProblemMissing, Severity: SeverityInfo})
+		return
+	}
+	// The directory may be a symbolic link, such as to a dotfiles repository, so the directories
+	// holding its target are checked as well, like SecureConfig does, and its target is walked
+	real, err := filepath.EvalSymlinks(dir)
+	holding := parents(dir)
+	if err == nil && real != dir {
+		holding = append(holding, parents(real)...)
+	}
+	for _, parent := range holding {
+		if !a.seen[parent] {
+			a.seen[parent] = true
+			a.findings = append(a.findings, checkPath(kind, parent)...)
+		}
+	}
+	if err != nil {
+		return
+	}
+	filepath.Walk(real, func(path string, info os.FileInfo, err error) error { // nolint: errcheck
+		if err != nil {
+			// Unreadable directories are skipped, the application can not read them either
+			return nil
+		}
+		rel, _ := filepath.Rel(real, path)
+		path = filepath.Join(dir, rel)
+		if a.seen[path] {
+			return nil
+		}
+		a.seen[path] = true
+		a.findings = append(a.findings, checkInfo(kind, path, info)...)
+		if info.Mode()&os.ModeSymlink != 0 {
+			if ok, err := within(dir, path); err == nil && !ok {
+				target, _ := filepath.EvalSymlinks(path)
+				a.findings = append(a.findings, Finding{Kind: kind, Path: path, Problem: ProblemSymlink, Severity: auditSeverity(kind), Detail: target})
+			}
+		}
+		return nil
+	})
+}
+
+// parents returns the directories holding path, from the root down
+func parents(path string) []string {
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if filepath.Dir(dir) == dir {
+			return dirs
+		}
+	}
+}
+
+// checkPath returns the problems with the owner and permissions of path, without following a symbolic link
+func checkPath(kind Kind, path string) []Finding {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+	return checkInfo(kind, path, info)
+}
+
+func checkInfo(kind Kind, path string, info os.FileInfo) []Finding {
+	var findings []Finding
+	if !ownedByUser(info) && !ownedByRoot(info) {
+		findings = append(findings, Finding{Kind: kind, Path: path, Problem: ProblemOwner, Severity: auditSeverity(kind)})
+	}
+	if writableByOthers(info) {
+		findings = append(findings, Finding{Kind: kind, Path: path, Problem: ProblemWritable, Severity: auditSeverity(kind), Detail: info.Mode().String()})
+	}
+	return findings
+}
+
+// writableByOthers reports whether users other than the owner can change the file.
+// Directories with the sticky bit, like /tmp, only let others add files of their own.
+func writableByOthers(info os.FileInfo) bool {
+	if !privatePerm || info.Mode()&os.ModeSymlink != 0 {
+		return false
+	}
+	return info.Mode().Perm()&0022 != 0 && !(info.IsDir() && info.Mode()&os.ModeSticky != 0)
+}
+
+// secure reports whether the file at path in dir, and every directory holding it, passes the checks of Audit
+func secure(dir, path string) bool {
+	if ok, err := within(dir, path); err != nil || !ok {
+		return false
+	}
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	for _, p := range append(parents(real), real) {
+		if len(checkPath(Config, p)) > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupAudit(t *testing.T) (string, func()) {
+	if !privatePerm {
+		t.Skip("permissions are not checked on this platform")
+	}
+	tmp, err := ioutil.TempDir("", "xdg-audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The temporary directory may be a symbolic link, such as /tmp on macOS
+	if tmp, err = filepath.EvalSymlinks(tmp); err != nil {
+		t.Fatal(err)
+	}
+	for env, dir := range map[string]string{
+		"XDG_DATA_HOME":   "data",
+		"XDG_DATA_DIRS":   "share",
+		"XDG_CONFIG_HOME": "config",
+		"XDG_CONFIG_DIRS": "etc",
+		"XDG_CACHE_HOME":  "cache",
+		"XDG_STATE_HOME":  "state",
+	} {
+		os.Setenv(env, filepath.Join(tmp, dir)) // nolint: errcheck
+	}
+	os.Unsetenv("XDG_RUNTIME_DIR") // nolint: errcheck
+	for _, dir := range []string{"config", "etc", "cache", "state", "share"} {
+		if err = os.MkdirAll(filepath.Join(tmp, dir, "OpenPeeDeeP", "XDG"), 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func writeAuditFile(t *testing.T, path string, perm os.FileMode) {
+	if err := ioutil.WriteFile(path, nil, perm); err != nil {
+		t.Fatal(err)
+	}
+	// Not masked by the umask
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXDG_Audit(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAudit(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	config := filepath.Join(tmp, "config", "OpenPeeDeeP", "XDG")
+	cache := filepath.Join(tmp, "cache", "OpenPeeDeeP", "XDG")
+	writeAuditFile(t, filepath.Join(config, "good.conf"), 0600)
+	writeAuditFile(t, filepath.Join(config, "bad.conf"), 0666)
+	writeAuditFile(t, filepath.Join(tmp, "outside.conf"), 0600)
+	assert.NoError(os.Symlink("good.conf", filepath.Join(config, "inside.conf")))
+	assert.NoError(os.Symlink(filepath.Join(tmp, "outside.conf"), filepath.Join(config, "escape.conf")))
+	assert.NoError(os.Chmod(cache, 0770))
+
+	findings := x.Audit()
+	assert.Contains(findings, Finding{Kind: Config, Path: filepath.Join(config, "bad.conf"), Problem: ProblemWritable, Severity: SeverityCritical, Detail: "-rw-rw-rw-"})
+	assert.Contains(findings, Finding{Kind: Config, Path: filepath.Join(config, "escape.conf"), Problem: ProblemSymlink, Severity: SeverityCritical, Detail: filepath.Join(tmp, "outside.conf")})
+	assert.Contains(findings, Finding{Kind: Cache, Path: cache, Problem: ProblemWritable, Severity: SeverityWarning, Detail: "drwxrwx---"})
+	assert.Contains(findings, Finding{Kind: Data, Path: filepath.Join(tmp, "data", "OpenPeeDeeP", "XDG"), Problem: ProblemMissing, Severity: SeverityInfo})
+	for _, f := range findings {
+		assert.NotEqual(filepath.Join(config, "good.conf"), f.Path, f.String())
+		assert.NotEqual(filepath.Join(config, "inside.conf"), f.Path, f.String())
+	}
+	assert.Equal("critical: /etc/app.conf is writable by others (-rw-rw-rw-)", Finding{Path: "/etc/app.conf", Problem: ProblemWritable, Severity: SeverityCritical, Detail: "-rw-rw-rw-"}.String())
+}
+
+func TestXDG_AuditSymlinkedDir(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAudit(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	shared := filepath.Join(tmp, "dotfiles", "shared")
+	assert.NoError(os.MkdirAll(filepath.Join(shared, "XDG"), 0700))
+	assert.NoError(os.Chmod(shared, 0777))
+	writeAuditFile(t, filepath.Join(shared, "XDG", "app.conf"), 0600)
+	config := filepath.Join(tmp, "config", "OpenPeeDeeP", "XDG")
+	assert.NoError(os.Remove(config))
+	assert.NoError(os.Symlink(filepath.Join(shared, "XDG"), config))
+
+	// Audit and SecureConfig agree about a directory linking into a tree others can write to
+	assert.Contains(x.Audit(), Finding{Kind: Config, Path: shared, Problem: ProblemWritable, Severity: SeverityCritical, Detail: "drwxrwxrwx"})
+	x.SecureConfig = true
+	assert.Equal("", x.QueryConfig("app.conf"))
+}
+
+func TestXDG_AuditOwner(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("files can only be given away by root")
+	}
+	assert := assert.New(t)
+	tmp, teardown := standupAudit(t)
+	defer teardown()
+	path := filepath.Join(tmp, "etc", "OpenPeeDeeP", "XDG", "app.conf")
+	writeAuditFile(t, path, 0644)
+	assert.NoError(os.Chown(path, 12345, 12345))
+
+	assert.Contains(New("OpenPeeDeeP", "XDG").Audit(), Finding{Kind: Config, Path: path, Problem: ProblemOwner, Severity: SeverityCritical})
+}
+
+func TestXDG_SecureConfig(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAudit(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	home := filepath.Join(tmp, "config", "OpenPeeDeeP", "XDG", "app.conf")
+	system := filepath.Join(tmp, "etc", "OpenPeeDeeP", "XDG", "app.conf")
+	writeAuditFile(t, home, 0666)
+	writeAuditFile(t, system, 0644)
+	writeAuditFile(t, filepath.Join(tmp, "outside.conf"), 0600)
+	assert.NoError(os.Symlink(filepath.Join(tmp, "outside.conf"), filepath.Join(tmp, "etc", "OpenPeeDeeP", "XDG", "escape.conf")))
+
+	assert.Equal(home, x.QueryConfig("app.conf"))
+	x.SecureConfig = true
+	assert.Equal(system, x.QueryConfig("app.conf"))
+	paths, err := x.QueryAll(Config, "app.conf")
+	assert.NoError(err)
+	assert.Equal([]string{system}, paths)
+	assert.Equal("", x.QueryConfig("escape.conf"))
+
+	assert.NoError(os.Chmod(filepath.Join(tmp, "etc", "OpenPeeDeeP"), 0777))
+	assert.Equal("", x.QueryConfig("app.conf"))
+}
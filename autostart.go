@@ -0,0 +1,159 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+)
+
+// AutostartEntry is a desktop entry that is launched when the user logs in
+type AutostartEntry struct {
+	// ID is the file name of the entry, such as com.vendor.app.desktop
+	ID   string
+	Path string
+	File *desktopentry.File
+	// Err is why the file could not be parsed, in which case File is nil
+	Err error
+}
+
+// AutostartDirs returns the directories holding autostart entries, the most important first
+func AutostartDirs() []string {
+	dirs := SearchDirs(Config)
+	for i, dir := range dirs {
+		dirs[i] = filepath.Join(dir, "autostart")
+	}
+	return dirs
+}
+
+// Autostarts returns the entries launched when the user logs in, sorted by ID.
+// An entry shadows entries with the same ID in less important directories. Entries that
+// are Hidden or not shown in the desktops listed in XDG_CURRENT_DESKTOP are left out.
+// Files that can not be parsed are not launched but still shadow other entries, and are returned with Err set.
+func Autostarts() ([]AutostartEntry, error) {
+	entries, err := autostarts()
+	if err != nil {
+		return nil, err
+	}
+	var enabled []AutostartEntry
+	for _, entry := range entries {
+		if entry.Err != nil || autostartEnabled(entry.File) {
+			enabled = append(enabled, entry)
+		}
+	}
+	sort.Slice(enabled, func(i, j int) bool {
+		return enabled[i].ID < enabled[j].ID
+	})
+	return enabled, nil
+}
+
+// autostarts returns the entry that takes effect for every ID, including hidden ones
+func autostarts() (map[string]AutostartEntry, error) {
+	entries := make(map[string]AutostartEntry)
+	for _, dir := range AutostartDirs() {
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range files {
+			id := info.Name()
+			if info.IsDir() || !strings.HasSuffix(id, ".desktop") {
+				continue
+			}
+			if _, ok := entries[id]; ok {
+				continue
+			}
+			path := filepath.Join(dir, id)
+			f, err := desktopentry.ParseFile(path)
+			entries[id] = AutostartEntry{ID: id, Path: path, File: f, Err: err}
+		}
+	}
+	return entries, nil
+}
+
+func autostartEnabled(f *desktopentry.File) bool {
+	main := f.Group(desktopentry.MainGroup)
+	if main == nil || main.Bool("Hidden") {
+		return false
+	}
+	desktops := strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":")
+	if main.Has("OnlyShowIn") {
+		return anyIn(desktops, main.Strings("OnlyShowIn"))
+	}
+	return !anyIn(desktops, main.Strings("NotShowIn"))
+}
+
+func anyIn(values, list []string) bool {
+	for _, value := range values {
+		for _, item := range list {
+			if value != "" && value == item {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AutostartID returns the file name of this application's autostart entry
+func (x *XDG) AutostartID() string {
+	return ReverseDNSNaming(Config, x.Vendor, x.Application) + ".desktop"
+}
+
+// EnableAutostart launches the running executable with args when the user logs in.
+// The entry is written to the autostart directory of ConfigHome.
+func (x *XDG) EnableAutostart(args ...string) error {
+	exe, err := executable()
+	if err != nil {
+		return err
+	}
+	f := desktopentry.New(desktopentry.Application, x.Application)
+	f.Main().Set("Exec", desktopentry.JoinExec(append([]string{exe}, args...)))
+	return f.Save(x.autostartPath())
+}
+
+// DisableAutostart stops this application from being launched when the user logs in.
+// The entry in ConfigHome is removed, or marked Hidden when it would otherwise uncover a system wide entry.
+func (x *XDG) DisableAutostart() error {
+	path := x.autostartPath()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	entries, err := autostarts()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[x.AutostartID()]; !ok {
+		return nil
+	}
+	f := desktopentry.New(desktopentry.Application, x.Application)
+	f.Main().SetBool("Hidden", true)
+	return f.Save(path)
+}
+
+// AutostartEnabled reports whether this application is launched when the user logs in.
+// The error of an entry that can not be parsed is returned.
+func (x *XDG) AutostartEnabled() (bool, error) {
+	entries, err := autostarts()
+	if err != nil {
+		return false, err
+	}
+	entry, ok := entries[x.AutostartID()]
+	if !ok || entry.Err != nil {
+		return false, entry.Err
+	}
+	return autostartEnabled(entry.File), nil
+}
+
+func (x *XDG) autostartPath() string {
+	return filepath.Join(ConfigHome(), "autostart", x.AutostartID())
+}
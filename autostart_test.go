@@ -0,0 +1,149 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+	"github.com/stretchr/testify/assert"
+)
+
+func standupAutostart(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-autostart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	configDirs := strings.Join([]string{filepath.Join(tmp, "system"), filepath.Join(tmp, "vendor")}, string(os.PathListSeparator))
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "home")) // nolint: errcheck
+	os.Setenv("XDG_CONFIG_DIRS", configDirs)                 // nolint: errcheck
+	os.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")         // nolint: errcheck
+	executable = func() (string, error) {
+		return "/opt/My App/app", nil
+	}
+	return tmp, func() {
+		executable = os.Executable
+		os.Unsetenv("XDG_CURRENT_DESKTOP") // nolint: errcheck
+		os.RemoveAll(tmp)                  // nolint: errcheck
+	}
+}
+
+func writeAutostart(t *testing.T, dir, id string, keys map[string]string) {
+	f := desktopentry.New(desktopentry.Application, id)
+	f.Main().Set("Exec", id)
+	for key, value := range keys {
+		f.Main().Set(key, value)
+	}
+	if err := f.Save(filepath.Join(dir, "autostart", id+".desktop")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAutostarts(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAutostart(t)
+	defer teardown()
+	home, system, vendor := filepath.Join(tmp, "home"), filepath.Join(tmp, "system"), filepath.Join(tmp, "vendor")
+	writeAutostart(t, system, "plain", nil)
+	writeAutostart(t, vendor, "plain", map[string]string{"Hidden": "true"})
+	writeAutostart(t, system, "hidden", nil)
+	writeAutostart(t, home, "hidden", map[string]string{"Hidden": "true"})
+	writeAutostart(t, vendor, "shadowed", nil)
+	writeAutostart(t, system, "shadowed", map[string]string{"Name": "system"})
+	writeAutostart(t, system, "gnome", map[string]string{"OnlyShowIn": "GNOME;"})
+	writeAutostart(t, system, "kde", map[string]string{"OnlyShowIn": "KDE;"})
+	writeAutostart(t, system, "notgnome", map[string]string{"NotShowIn": "GNOME;"})
+	if err := ioutil.WriteFile(filepath.Join(system, "autostart", "broken.desktop"), []byte("broken"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	// A file that can not be parsed still shadows the system wide entry
+	writeAutostart(t, system, "shadowedbroken", nil)
+	if err := ioutil.WriteFile(filepath.Join(home, "autostart", "shadowedbroken.desktop"), []byte("broken"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal([]string{
+		filepath.Join(home, "autostart"),
+		filepath.Join(system, "autostart"),
+		filepath.Join(vendor, "autostart"),
+	}, AutostartDirs())
+	entries, err := Autostarts()
+	assert.NoError(err)
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	assert.Equal([]string{"broken.desktop", "gnome.desktop", "plain.desktop", "shadowed.desktop", "shadowedbroken.desktop"}, ids)
+	if assert.Len(entries, 5) {
+		assert.Error(entries[0].Err)
+		assert.Nil(entries[0].File)
+		assert.NoError(entries[1].Err)
+		assert.Equal("system", entries[3].File.Main().String("Name"))
+		assert.Equal(filepath.Join(system, "autostart", "shadowed.desktop"), entries[3].Path)
+		assert.Equal(filepath.Join(home, "autostart", "shadowedbroken.desktop"), entries[4].Path)
+		assert.Error(entries[4].Err)
+	}
+}
+
+func TestXDG_Autostart(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAutostart(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	path := filepath.Join(tmp, "home", "autostart", "com.openpeedeep.xdg.desktop")
+
+	enabled, err := x.AutostartEnabled()
+	assert.NoError(err)
+	assert.False(enabled)
+
+	assert.NoError(x.EnableAutostart("--minimized"))
+	enabled, err = x.AutostartEnabled()
+	assert.NoError(err)
+	assert.True(enabled)
+	f, err := desktopentry.ParseFile(path)
+	if assert.NoError(err) {
+		assert.NoError(f.Validate())
+		args, err := f.ExecArgs()
+		assert.NoError(err)
+		assert.Equal([]string{"/opt/My App/app", "--minimized"}, args)
+	}
+
+	assert.NoError(x.DisableAutostart())
+	enabled, err = x.AutostartEnabled()
+	assert.NoError(err)
+	assert.False(enabled)
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+
+	assert.NoError(ioutil.WriteFile(path, []byte("broken"), 0600))
+	enabled, err = x.AutostartEnabled()
+	assert.Error(err)
+	assert.False(enabled)
+}
+
+func TestXDG_DisableSystemAutostart(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAutostart(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	writeAutostart(t, filepath.Join(tmp, "system"), "com.openpeedeep.xdg", nil)
+
+	enabled, err := x.AutostartEnabled()
+	assert.NoError(err)
+	assert.True(enabled)
+
+	assert.NoError(x.DisableAutostart())
+	enabled, err = x.AutostartEnabled()
+	assert.NoError(err)
+	assert.False(enabled)
+	f, err := desktopentry.ParseFile(filepath.Join(tmp, "home", "autostart", "com.openpeedeep.xdg.desktop"))
+	if assert.NoError(err) {
+		assert.True(f.Main().Bool("Hidden"))
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+)
+
+// InstallExecutable writes the contents of r to an executable named name in BinHome.
+// The file is written next to its destination and renamed into place, so an existing
+// executable is replaced atomically. Returns the path of the installed executable.
+func InstallExecutable(name string, r io.Reader) (string, error) {
+	dir := BinHome()
+	if dir == "" {
+		return "", ErrUnsupported
+	}
+	if !plainName(name) {
+		return "", &UnsafeNameError{Name: name, Dir: dir}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	err := atomicfile.Write(path, 0755, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// InPath reports whether dir is one of the directories listed in the PATH environment variable.
+// Use it with BinHome to warn users that installed executables can not be run by name.
+func InPath(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	dir = filepath.Clean(dir)
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		resolved = dir
+	}
+	for _, entry := range filepath.SplitList(os.Getenv(pathEnv())) {
+		if entry == "" {
+			continue
+		}
+		entry = filepath.Clean(entry)
+		if samePath(entry, dir) {
+			return true
+		}
+		if real, err := filepath.EvalSymlinks(entry); err == nil && samePath(real, resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathEnv returns the name of the variable listing the directories searched for executables
+func pathEnv() string {
+	if runtime.GOOS == "plan9" {
+		return "path"
+	}
+	return "PATH"
+}
+
+func samePath(a, b string) bool {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstallExecutable(t *testing.T) {
+	assert := assert.New(t)
+	tmp, err := ioutil.TempDir("", "xdg-bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	binHome := filepath.Join(tmp, "bin")
+	os.Setenv("XDG_BIN_HOME", binHome) // nolint: errcheck
+
+	path, err := InstallExecutable("tool", bytes.NewBufferString("first"))
+	assert.NoError(err)
+	assert.Equal(filepath.Join(binHome, "tool"), path)
+
+	path, err = InstallExecutable("tool", bytes.NewBufferString("second"))
+	assert.NoError(err)
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("second", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		assert.NoError(err)
+		assert.Equal(os.FileMode(0755), info.Mode().Perm())
+	}
+	files, err := ioutil.ReadDir(binHome)
+	assert.NoError(err)
+	assert.Len(files, 1)
+}
+
+func TestInstallExecutableUnsafe(t *testing.T) {
+	os.Setenv("XDG_BIN_HOME", filepath.Clean("/some/path")) // nolint: errcheck
+	for _, name := range []string{"", ".", "..", filepath.Join("..", "tool"), "sub/tool"} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			_, err := InstallExecutable(name, bytes.NewBufferString("tool"))
+			assert.IsType(&UnsafeNameError{}, err)
+		})
+	}
+}
+
+func TestInPath(t *testing.T) {
+	assert := assert.New(t)
+	path := os.Getenv(pathEnv())
+	defer os.Setenv(pathEnv(), path) // nolint: errcheck
+	dirs := []string{filepath.Clean("/some/bin"), "", filepath.Clean("/other/bin") + string(filepath.Separator)}
+	os.Setenv(pathEnv(), strings.Join(dirs, string(os.PathListSeparator))) // nolint: errcheck
+
+	assert.True(InPath(filepath.Clean("/some/bin")))
+	assert.True(InPath(filepath.Clean("/other/bin")))
+	assert.False(InPath(filepath.Clean("/missing/bin")))
+	assert.False(InPath(""))
+}
@@ -0,0 +1,509 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package desktopentry reads and writes files in the Desktop Entry format.
+//
+// See https://specifications.freedesktop.org/desktop-entry-spec/latest/ for the format.
+package desktopentry
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+)
+
+// MainGroup is the name of the group every desktop entry file starts with
+const MainGroup = "Desktop Entry"
+
+// The values of the Type key
+const (
+	Application = "Application"
+	Link        = "Link"
+	Directory   = "Directory"
+)
+
+// SyntaxError is returned when a desktop entry file can not be parsed
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("desktopentry: line %d: %s", e.Line, e.Msg)
+}
+
+// File is a parsed desktop entry file.
+// Values and comments are kept as they are written in the file, so a File is written back unchanged.
+type File struct {
+	// Path is the location the file was read from or saved to. It is used for the %k field code.
+	Path string
+
+	// comments are only used by files without groups
+	comments []string
+	groups   []*Group
+}
+
+// Group is a named section of a desktop entry file
+type Group struct {
+	Name string
+	// comments are the comment and blank lines written before the group header
+	comments []string
+	lines    []line
+}
+
+// line is either a key and its value or a comment
+type line struct {
+	key     string
+	locale  string
+	value   string
+	comment string
+}
+
+// New returns a file with a main group holding the given type and name
+func New(typ, name string) *File {
+	f := new(File)
+	g := f.AddGroup(MainGroup)
+	g.Set("Type", typ)
+	g.Set("Name", name)
+	return f
+}
+
+// Parse reads a desktop entry file from r.
+// Like GLib, a key written twice takes the last value and the keys of a group written twice are merged into the first one.
+func Parse(r io.Reader) (*File, error) {
+	f := new(File)
+	var group *Group
+	var comments []string
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || text[0] == '#':
+			comments = append(comments, text)
+		case text[0] == '[':
+			if text[len(text)-1] != ']' {
+				return nil, &SyntaxError{n, "unterminated group header"}
+			}
+			name := text[1 : len(text)-1]
+			if name == "" || strings.ContainsAny(name, "[]") {
+				return nil, &SyntaxError{n, fmt.Sprintf("invalid group name %q", name)}
+			}
+			if group = f.Group(name); group != nil {
+				group.addComments(comments)
+			} else {
+				group = f.AddGroup(name)
+				group.comments = comments
+			}
+			comments = nil
+		default:
+			if group == nil {
+				return nil, &SyntaxError{n, "key outside of a group"}
+			}
+			key, locale, value, err := splitLine(text)
+			if err != nil {
+				return nil, &SyntaxError{n, err.Error()}
+			}
+			group.addComments(comments)
+			comments = nil
+			if i := group.index(key, locale); i >= 0 {
+				group.lines[i].value = value
+				continue
+			}
+			group.lines = append(group.lines, line{key: key, locale: locale, value: value})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if group == nil {
+		f.comments = comments
+	} else {
+		group.addComments(comments)
+	}
+	return f, nil
+}
+
+// ParseFile reads the desktop entry file at path
+func ParseFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint: errcheck
+	f, err := Parse(file)
+	if err != nil {
+		return nil, err
+	}
+	f.Path = path
+	return f, nil
+}
+
+func splitLine(text string) (string, string, string, error) {
+	eq := strings.IndexByte(text, '=')
+	if eq < 0 {
+		return "", "", "", fmt.Errorf("missing '=' in %q", text)
+	}
+	key := strings.TrimSpace(text[:eq])
+	value := strings.TrimSpace(text[eq+1:])
+	locale := ""
+	if open := strings.IndexByte(key, '['); open >= 0 {
+		if !strings.HasSuffix(key, "]") || open == len(key)-2 {
+			return "", "", "", fmt.Errorf("invalid locale in key %q", key)
+		}
+		locale = key[open+1 : len(key)-1]
+		key = key[:open]
+	}
+	if !validKey(key) {
+		return "", "", "", fmt.Errorf("invalid key %q", key)
+	}
+	return key, locale, value, nil
+}
+
+// validKey reports whether key only holds the characters A-Za-z0-9-
+func validKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func keyName(key, locale string) string {
+	if locale == "" {
+		return key
+	}
+	return key + "[" + locale + "]"
+}
+
+// Groups returns the groups of the file in the order they are written
+func (f *File) Groups() []*Group {
+	return f.groups
+}
+
+// Group returns the group with the given name or nil if there is none
+func (f *File) Group(name string) *Group {
+	for _, g := range f.groups {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Main returns the Desktop Entry group, adding it when it is missing
+func (f *File) Main() *Group {
+	if g := f.Group(MainGroup); g != nil {
+		return g
+	}
+	g := &Group{Name: MainGroup}
+	f.groups = append([]*Group{g}, f.groups...)
+	return g
+}
+
+// AddGroup returns the group with the given name, adding it to the end of the file when it is missing
+func (f *File) AddGroup(name string) *Group {
+	if g := f.Group(name); g != nil {
+		return g
+	}
+	g := &Group{Name: name}
+	f.groups = append(f.groups, g)
+	return g
+}
+
+// WriteTo writes the file to w in the canonical format
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	buf := new(bytes.Buffer)
+	for _, comment := range f.comments {
+		buf.WriteString(comment + "\n")
+	}
+	for i, g := range f.groups {
+		if i > 0 && len(g.comments) == 0 {
+			buf.WriteString("\n")
+		}
+		for _, comment := range g.comments {
+			buf.WriteString(comment + "\n")
+		}
+		buf.WriteString("[" + g.Name + "]\n")
+		for _, l := range g.lines {
+			if l.key == "" {
+				buf.WriteString(l.comment + "\n")
+				continue
+			}
+			buf.WriteString(keyName(l.key, l.locale) + "=" + l.value + "\n")
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// Save writes the file to path, replacing any existing file atomically
+func (f *File) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	err := atomicfile.Write(path, 0644, func(w io.Writer) error {
+		_, err := f.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	f.Path = path
+	return nil
+}
+
+func (g *Group) addComments(comments []string) {
+	for _, comment := range comments {
+		g.lines = append(g.lines, line{comment: comment})
+	}
+}
+
+func (g *Group) index(key, locale string) int {
+	for i, l := range g.lines {
+		if l.key != "" && l.key == key && l.locale == locale {
+			return i
+		}
+	}
+	return -1
+}
+
+// Keys returns the names of the keys in the group, including their locale, in the order they are written
+func (g *Group) Keys() []string {
+	var keys []string
+	for _, l := range g.lines {
+		if l.key != "" {
+			keys = append(keys, keyName(l.key, l.locale))
+		}
+	}
+	return keys
+}
+
+// Raw returns the value of key as it is written in the file
+func (g *Group) Raw(key string) (string, bool) {
+	return g.rawLocale(key, "")
+}
+
+func (g *Group) rawLocale(key, locale string) (string, bool) {
+	i := g.index(key, locale)
+	if i < 0 {
+		return "", false
+	}
+	return g.lines[i].value, true
+}
+
+// SetRaw sets the value of key exactly as it should be written in the file
+func (g *Group) SetRaw(key, value string) {
+	g.setRawLocale(key, "", value)
+}
+
+func (g *Group) setRawLocale(key, locale, value string) {
+	if i := g.index(key, locale); i >= 0 {
+		g.lines[i].value = value
+		return
+	}
+	g.lines = append(g.lines, line{key: key, locale: locale, value: value})
+}
+
+// Delete removes key, including all of its localized values, from the group
+func (g *Group) Delete(key string) {
+	lines := g.lines[:0]
+	for _, l := range g.lines {
+		if l.key != key {
+			lines = append(lines, l)
+		}
+	}
+	g.lines = lines
+}
+
+// Has reports whether the group has an unlocalized value for key
+func (g *Group) Has(key string) bool {
+	return g.index(key, "") >= 0
+}
+
+// String returns the unescaped value of key
+func (g *Group) String(key string) string {
+	value, _ := g.Raw(key)
+	return unescape(value, false)
+}
+
+// Set sets key to value, escaping it as needed
+func (g *Group) Set(key, value string) {
+	g.SetRaw(key, escape(value, false))
+}
+
+// LocaleString returns the value of key best matching locale, such as de_DE.UTF-8 or sr@latin.
+// The unlocalized value is returned when no localized value matches.
+func (g *Group) LocaleString(key, locale string) string {
+	for _, candidate := range localeCandidates(locale) {
+		if value, ok := g.rawLocale(key, candidate); ok {
+			return unescape(value, false)
+		}
+	}
+	return g.String(key)
+}
+
+// SetLocaleString sets the value of key for locale
+func (g *Group) SetLocaleString(key, locale, value string) {
+	g.setRawLocale(key, locale, escape(value, false))
+}
+
+// Bool returns true when key is set to true
+func (g *Group) Bool(key string) bool {
+	value, _ := g.Raw(key)
+	return value == "true"
+}
+
+// SetBool sets key to true or false
+func (g *Group) SetBool(key string, value bool) {
+	if value {
+		g.SetRaw(key, "true")
+		return
+	}
+	g.SetRaw(key, "false")
+}
+
+// Strings returns the unescaped elements of the list value of key
+func (g *Group) Strings(key string) []string {
+	value, _ := g.Raw(key)
+	return splitList(value)
+}
+
+// SetStrings sets key to a list of values
+func (g *Group) SetStrings(key string, values []string) {
+	var buf bytes.Buffer
+	for _, value := range values {
+		buf.WriteString(escape(value, true))
+		buf.WriteByte(';')
+	}
+	g.SetRaw(key, buf.String())
+}
+
+// localeCandidates returns the keys to look for in order of preference, as defined by the specification.
+// The encoding of the locale is ignored.
+func localeCandidates(locale string) []string {
+	if dot := strings.IndexByte(locale, '.'); dot >= 0 {
+		rest := ""
+		if at := strings.IndexByte(locale[dot:], '@'); at >= 0 {
+			rest = locale[dot+at:]
+		}
+		locale = locale[:dot] + rest
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return nil
+	}
+	lang, country, modifier := locale, "", ""
+	if at := strings.IndexByte(lang, '@'); at >= 0 {
+		lang, modifier = lang[:at], lang[at+1:]
+	}
+	if us := strings.IndexByte(lang, '_'); us >= 0 {
+		lang, country = lang[:us], lang[us+1:]
+	}
+	var candidates []string
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	return append(candidates, lang)
+}
+
+// Locale returns the locale of messages for the current process from LC_ALL, LC_MESSAGES or LANG
+func Locale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+func escape(value string, list bool) string {
+	var buf bytes.Buffer
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			buf.WriteString(`\\`)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == ' ' && (i == 0 || i == len(value)-1):
+			buf.WriteString(`\s`)
+		case r == ';' && list:
+			buf.WriteString(`\;`)
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+func unescape(value string, list bool) string {
+	if !strings.ContainsRune(value, '\\') {
+		return value
+	}
+	var buf bytes.Buffer
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			buf.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			buf.WriteByte(' ')
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'r':
+			buf.WriteByte('\r')
+		case '\\':
+			buf.WriteByte('\\')
+		case ';':
+			if !list {
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(';')
+		default:
+			buf.WriteByte('\\')
+			buf.WriteByte(value[i])
+		}
+	}
+	return buf.String()
+}
+
+func splitList(value string) []string {
+	var values []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case ';':
+			values = append(values, unescape(value[start:i], true))
+			start = i + 1
+		}
+	}
+	if start < len(value) {
+		values = append(values, unescape(value[start:], true))
+	}
+	return values
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package desktopentry
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `# Written by hand
+[Desktop Entry]
+Version=1.5
+Type=Application
+Name=Text Editor
+Name[de]=Texteditor
+Name[sr@latin]=Uređivač teksta
+Comment=Edit\stext files\nquickly
+Exec=editor --name "My Editor" %F
+Icon=editor
+MimeType=text/plain;text/x-c\;plus;
+Terminal=false
+Actions=new-window;
+
+# An action
+[Desktop Action new-window]
+Name=New Window
+Exec=editor --new-window
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	f, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(err) {
+		return
+	}
+	var buf bytes.Buffer
+	_, err = f.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Equal(sample, buf.String())
+	assert.Len(f.Groups(), 2)
+	assert.Equal([]string{"Version", "Type", "Name", "Name[de]", "Name[sr@latin]", "Comment", "Exec", "Icon", "MimeType", "Terminal", "Actions"}, f.Main().Keys())
+}
+
+func TestGroupValues(t *testing.T) {
+	assert := assert.New(t)
+	f, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(err) {
+		return
+	}
+	main := f.Main()
+	assert.Equal("Edit text files\nquickly", main.String("Comment"))
+	assert.Equal([]string{"text/plain", "text/x-c;plus"}, main.Strings("MimeType"))
+	assert.False(main.Bool("Terminal"))
+	assert.Equal("", main.String("Missing"))
+	assert.Equal("New Window", f.Group("Desktop Action new-window").String("Name"))
+	assert.Nil(f.Group("Missing"))
+}
+
+type localeTestCase struct {
+	locale   string
+	expected string
+}
+
+func TestLocaleString(t *testing.T) {
+	f, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []localeTestCase{
+		{"", "Text Editor"},
+		{"C", "Text Editor"},
+		{"de", "Texteditor"},
+		{"de_DE", "Texteditor"},
+		{"de_DE.UTF-8", "Texteditor"},
+		{"de_AT@euro", "Texteditor"},
+		{"sr_RS.UTF-8@latin", "Uređivač teksta"},
+		{"sr_RS", "Text Editor"},
+		{"fr_FR", "Text Editor"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.locale, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expected, f.Main().LocaleString("Name", tc.locale))
+		})
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	assert := assert.New(t)
+	f := New(Application, "Tool")
+	main := f.Main()
+	main.Set("Comment", " leading\tand\\slash ")
+	main.SetLocaleString("Name", "de", "Werkzeug")
+	main.SetStrings("Categories", []string{"Utility", "a;b"})
+	main.SetBool("Terminal", true)
+	main.Set("Exec", "tool %U")
+	f.AddGroup("Desktop Action open").Set("Name", "Open")
+
+	var buf bytes.Buffer
+	_, err := f.WriteTo(&buf)
+	assert.NoError(err)
+	expected := "[Desktop Entry]\nType=Application\nName=Tool\nComment=\\sleading\\tand\\\\slash\\s\nName[de]=Werkzeug\nCategories=Utility;a\\;b;\nTerminal=true\nExec=tool %U\n\n[Desktop Action open]\nName=Open\n"
+	assert.Equal(expected, buf.String())
+
+	parsed, err := Parse(&buf)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(" leading\tand\\slash ", parsed.Main().String("Comment"))
+	assert.Equal([]string{"Utility", "a;b"}, parsed.Main().Strings("Categories"))
+	assert.True(parsed.Main().Bool("Terminal"))
+
+	main.Delete("Name")
+	assert.False(main.Has("Name"))
+	assert.Equal("", main.LocaleString("Name", "de"))
+}
+
+func TestParseDuplicates(t *testing.T) {
+	assert := assert.New(t)
+	f, err := Parse(strings.NewReader("[Desktop Entry]\nName=a\nIcon=app\nName=b\n[Extra]\nKey=1\n[Desktop Entry]\nIcon=other\nExec=app\n"))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Len(f.Groups(), 2)
+	main := f.Main()
+	assert.Equal([]string{"Name", "Icon", "Exec"}, main.Keys())
+	assert.Equal("b", main.String("Name"))
+	assert.Equal("other", main.String("Icon"))
+	assert.Equal("1", f.Group("Extra").String("Key"))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"Key=Value\n",
+		"[Desktop Entry\n",
+		"[Desktop Entry]\nNoEquals\n",
+		"[Desktop Entry]\nBad_Key=1\n",
+		"[Desktop Entry]\nName[=a\n",
+	} {
+		t.Run(input, func(t *testing.T) {
+			assert := assert.New(t)
+			_, err := Parse(strings.NewReader(input))
+			assert.IsType(&SyntaxError{}, err)
+		})
+	}
+}
+
+type execTestCase struct {
+	name     string
+	exec     string
+	targets  []string
+	expected []string
+}
+
+func TestExecArgs(t *testing.T) {
+	testCases := []execTestCase{
+		{"Files", "editor %F", []string{"a.txt", "b.txt"}, []string{"editor", "a.txt", "b.txt"}},
+		{"File", "editor %f", []string{"a.txt", "b.txt"}, []string{"editor", "a.txt"}},
+		{"No Targets", "editor %u", nil, []string{"editor"}},
+		{"Quoted", `"/opt/My App/app" --title "say \"hi\"" %U`, []string{"http://x"}, []string{"/opt/My App/app", "--title", `say "hi"`, "http://x"}},
+		{"Icon", "editor %i", nil, []string{"editor", "--icon", "editor"}},
+		{"Codes", "editor --name=%c --desktop=%k 100%%", nil, []string{"editor", "--name=Editor", "--desktop=/apps/editor.desktop", "100%"}},
+		{"Deprecated", "editor %d %m", nil, []string{"editor"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			f := New(Application, "Editor")
+			f.Path = "/apps/editor.desktop"
+			f.Main().Set("Icon", "editor")
+			f.Main().Set("Exec", tc.exec)
+			actual, err := f.ExecArgs(tc.targets...)
+			assert.NoError(err)
+			assert.Equal(tc.expected, actual)
+		})
+	}
+}
+
+func TestJoinExec(t *testing.T) {
+	assert := assert.New(t)
+	args := []string{"/opt/My App/app", "--flag", `say "$hi"`, "100%", ""}
+	exec := JoinExec(args)
+	assert.Equal(`"/opt/My App/app" --flag "say \"\$hi\"" 100%% ""`, exec)
+
+	f := New(Application, "App")
+	f.Main().Set("Exec", exec)
+	actual, err := f.ExecArgs()
+	assert.NoError(err)
+	assert.Equal(args, actual)
+}
+
+func TestExecArgsErrors(t *testing.T) {
+	assert := assert.New(t)
+	f := New(Application, "Editor")
+	_, err := f.ExecArgs()
+	assert.Equal(ErrNoExec, err)
+
+	f.Main().Set("Exec", `editor "unterminated`)
+	_, err = f.ExecArgs()
+	assert.Error(err)
+
+	f.Main().Set("Exec", "editor %x")
+	_, err = f.ExecArgs()
+	assert.Error(err)
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	f, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.NoError(f.Validate())
+
+	f.Main().Delete("Exec")
+	f.Main().SetRaw("Terminal", "yes")
+	f.Main().Set("NotShowIn", "KDE;")
+	f.Main().Set("OnlyShowIn", "GNOME;")
+	err = f.Validate()
+	if assert.IsType(&ValidationError{}, err) {
+		assert.Len(err.(*ValidationError).Problems, 3)
+	}
+
+	link := New(Link, "Home Page")
+	assert.Error(link.Validate())
+	link.Main().Set("URL", "https://example.com")
+	assert.NoError(link.Validate())
+
+	empty := new(File)
+	assert.Error(empty.Validate())
+}
+
+func TestFind(t *testing.T) {
+	assert := assert.New(t)
+	tmp, err := ioutil.TempDir("", "desktopentry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	home := filepath.Join(tmp, "home")
+	system := filepath.Join(tmp, "system")
+	files := []string{
+		filepath.Join(home, ApplicationsDir, "editor.desktop"),
+		filepath.Join(system, ApplicationsDir, "editor.desktop"),
+		filepath.Join(system, ApplicationsDir, "viewer.desktop"),
+		filepath.Join(system, ApplicationsDir, "kde", "okular.desktop"),
+	}
+	for _, file := range files {
+		assert.NoError(New(Application, "Test").Save(file))
+	}
+	dirs := []string{home, filepath.Join(tmp, "missing"), system}
+
+	path, err := Find("editor.desktop", dirs)
+	assert.NoError(err)
+	assert.Equal(files[0], path)
+	path, err = Find("viewer.desktop", dirs)
+	assert.NoError(err)
+	assert.Equal(files[2], path)
+	path, err = Find("kde-okular.desktop", dirs)
+	assert.NoError(err)
+	assert.Equal(files[3], path)
+	path, err = Find("missing.desktop", dirs)
+	assert.NoError(err)
+	assert.Equal("", path)
+
+	all, err := Scan(dirs)
+	assert.NoError(err)
+	assert.Equal(map[string]string{
+		"editor.desktop":     files[0],
+		"viewer.desktop":     files[2],
+		"kde-okular.desktop": files[3],
+	}, all)
+
+	parsed, err := ParseFile(files[3])
+	assert.NoError(err)
+	assert.Equal(files[3], parsed.Path)
+	assert.Equal("Test", parsed.Main().String("Name"))
+}
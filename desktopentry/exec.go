@@ -0,0 +1,157 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package desktopentry
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoExec is returned when an entry does not have an Exec key
+var ErrNoExec = errors.New("desktopentry: no Exec key")
+
+// SplitExec splits the value of an Exec key into its arguments, removing the quoting.
+// Field codes are left in place.
+func SplitExec(exec string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case quoted && c == '\\':
+			if i+1 == len(exec) {
+				return nil, fmt.Errorf("desktopentry: trailing backslash in %q", exec)
+			}
+			i++
+			arg.WriteByte(exec[i])
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && c == ' ':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("desktopentry: unterminated quote in %q", exec)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// JoinExec quotes args into the value of an Exec key.
+// Percent signs are doubled, so none of the arguments are taken as field codes.
+func JoinExec(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.Replace(arg, "%", "%%", -1)
+		if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\><~|&;$*?#()`") {
+			quoted[i] = arg
+			continue
+		}
+		var buf strings.Builder
+		buf.WriteByte('"')
+		for j := 0; j < len(arg); j++ {
+			switch arg[j] {
+			case '"', '`', '$', '\\':
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(arg[j])
+		}
+		buf.WriteByte('"')
+		quoted[i] = buf.String()
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ExecArgs returns the command line to launch the entry with the given files or URLs.
+// The field codes %f and %u are replaced by the first target, %F and %U by all of them,
+// %i by the icon, %c by the name in the current locale and %k by the location of the file.
+// The deprecated field codes are removed.
+func (f *File) ExecArgs(targets ...string) ([]string, error) {
+	main := f.Group(MainGroup)
+	if main == nil || !main.Has("Exec") {
+		return nil, ErrNoExec
+	}
+	args, err := SplitExec(main.String("Exec"))
+	if err != nil {
+		return nil, err
+	}
+	var cmd []string
+	for _, arg := range args {
+		switch arg {
+		case "%f", "%u":
+			if len(targets) > 0 {
+				cmd = append(cmd, targets[0])
+			}
+			continue
+		case "%F", "%U":
+			cmd = append(cmd, targets...)
+			continue
+		case "%i":
+			if icon := main.String("Icon"); icon != "" {
+				cmd = append(cmd, "--icon", icon)
+			}
+			continue
+		}
+		expanded, err := f.expandCodes(main, arg, targets)
+		if err != nil {
+			return nil, err
+		}
+		if expanded != "" || !strings.Contains(arg, "%") {
+			cmd = append(cmd, expanded)
+		}
+	}
+	if len(cmd) == 0 {
+		return nil, ErrNoExec
+	}
+	return cmd, nil
+}
+
+// expandCodes replaces the field codes found inside of an argument
+func (f *File) expandCodes(main *Group, arg string, targets []string) (string, error) {
+	if !strings.Contains(arg, "%") {
+		return arg, nil
+	}
+	var buf strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' {
+			buf.WriteByte(arg[i])
+			continue
+		}
+		if i+1 == len(arg) {
+			return "", fmt.Errorf("desktopentry: incomplete field code in %q", arg)
+		}
+		i++
+		switch arg[i] {
+		case '%':
+			buf.WriteByte('%')
+		case 'f', 'u':
+			if len(targets) > 0 {
+				buf.WriteString(targets[0])
+			}
+		case 'c':
+			buf.WriteString(main.LocaleString("Name", Locale()))
+		case 'k':
+			buf.WriteString(f.Path)
+		case 'i':
+			buf.WriteString(main.String("Icon"))
+		case 'd', 'D', 'n', 'N', 'v', 'm':
+		default:
+			return "", fmt.Errorf("desktopentry: invalid field code %%%c in %q", arg[i], arg)
+		}
+	}
+	return buf.String(), nil
+}
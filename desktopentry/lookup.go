@@ -0,0 +1,95 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package desktopentry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApplicationsDir is the directory below each data directory that holds application desktop files
+const ApplicationsDir = "applications"
+
+// Find returns the path of the desktop file with the given desktop file ID, such as org.gnome.Maps.desktop.
+// The applications directory of each of dataDirs is searched in order, as returned by xdg.SearchDirs(xdg.Data).
+// Returns an empty string if one was not found.
+func Find(id string, dataDirs []string) (string, error) {
+	for _, dir := range dataDirs {
+		appDir := filepath.Join(dir, ApplicationsDir)
+		path := filepath.Join(appDir, id)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		if !strings.Contains(id, "-") {
+			continue
+		}
+		ids, err := scanDir(appDir)
+		if err != nil {
+			return "", err
+		}
+		if path, ok := ids[id]; ok {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// Scan returns the path of every desktop file in the applications directories of dataDirs keyed by desktop file ID.
+// A file in an earlier directory shadows a file with the same ID in a later one.
+func Scan(dataDirs []string) (map[string]string, error) {
+	found := make(map[string]string)
+	for _, dir := range dataDirs {
+		ids, err := scanDir(filepath.Join(dir, ApplicationsDir))
+		if err != nil {
+			return nil, err
+		}
+		for id, path := range ids {
+			if _, ok := found[id]; !ok {
+				found[id] = path
+			}
+		}
+	}
+	return found, nil
+}
+
+// ID returns the desktop file ID of the file at path below appDir.
+// Subdirectories become part of the ID separated by dashes, so kde/okular.desktop is kde-okular.desktop.
+func ID(appDir, path string) (string, error) {
+	rel, err := filepath.Rel(appDir, path)
+	if err != nil {
+		return "", err
+	}
+	return strings.Replace(filepath.ToSlash(rel), "/", "-", -1), nil
+}
+
+func scanDir(appDir string) (map[string]string, error) {
+	ids := make(map[string]string)
+	root, err := filepath.EvalSymlinks(appDir)
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".desktop") {
+			return nil
+		}
+		id, err := ID(root, path)
+		if err != nil {
+			return err
+		}
+		ids[id] = filepath.Join(appDir, strings.TrimPrefix(path, root))
+		return nil
+	})
+	return ids, err
+}
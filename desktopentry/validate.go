@@ -0,0 +1,75 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package desktopentry
+
+import (
+	"strings"
+)
+
+// ValidationError lists the problems found by Validate
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "desktopentry: invalid file: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the file against the required keys and values of the specification.
+// Returns a *ValidationError describing every problem found.
+func (f *File) Validate() error {
+	var problems []string
+	if len(f.groups) == 0 || f.groups[0].Name != MainGroup {
+		problems = append(problems, "the first group must be ["+MainGroup+"]")
+	}
+	main := f.Group(MainGroup)
+	if main == nil {
+		return &ValidationError{problems}
+	}
+	for _, key := range []string{"Type", "Name"} {
+		if main.String(key) == "" {
+			problems = append(problems, "missing required key "+key)
+		}
+	}
+	switch typ := main.String("Type"); typ {
+	case Application:
+		if !main.Has("Exec") && !main.Bool("DBusActivatable") {
+			problems = append(problems, "an Application needs an Exec key")
+		}
+		if main.Has("Exec") {
+			if _, err := SplitExec(main.String("Exec")); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	case Link:
+		if main.String("URL") == "" {
+			problems = append(problems, "a Link needs a URL key")
+		}
+	case Directory, "":
+	default:
+		if !strings.HasPrefix(typ, "X-") {
+			problems = append(problems, "unknown Type "+typ)
+		}
+	}
+	for _, key := range []string{"Hidden", "NoDisplay", "Terminal", "StartupNotify", "DBusActivatable", "PrefersNonDefaultGPU", "SingleMainWindow"} {
+		if value, ok := main.Raw(key); ok && value != "true" && value != "false" {
+			problems = append(problems, key+" must be true or false")
+		}
+	}
+	if main.Has("OnlyShowIn") && main.Has("NotShowIn") {
+		problems = append(problems, "only one of OnlyShowIn and NotShowIn may be used")
+	}
+	for _, g := range f.groups {
+		if g.Name != MainGroup && strings.HasPrefix(g.Name, "Desktop Action ") {
+			if g.String("Name") == "" {
+				problems = append(problems, "["+g.Name+"] needs a Name key")
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{problems}
+	}
+	return nil
+}
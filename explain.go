@@ -0,0 +1,73 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import "os"
+
+// Source describes where a resolved directory came from
+type Source int
+
+// The places a directory can be resolved from
+const (
+	// SourceDefault is the operating system default location
+	SourceDefault Source = iota
+	// SourceXDG is one of the XDG_* environment variables
+	SourceXDG
+	// SourceApp is one of the application's EnvPrefix environment variables
+	SourceApp
+	// SourcePortable is the directory of the running executable
+	SourcePortable
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceXDG:
+		return "xdg"
+	case SourceApp:
+		return "app"
+	case SourcePortable:
+		return "portable"
+	}
+	return "unknown"
+}
+
+// Explanation describes how the home directory of a Kind was resolved
+type Explanation struct {
+	Kind   Kind
+	Path   string
+	Source Source
+	// Env is the environment variable the path came from. It is empty for
+	// SourceDefault and for SourcePortable unless PortableEnv turned it on.
+	Env string
+}
+
+// Explain reports which location is used for the home directory of kind and why
+func (x *XDG) Explain(kind Kind) Explanation {
+	if dir, env := x.override(kind); dir != "" {
+		return Explanation{Kind: kind, Path: dir, Source: SourceApp, Env: env}
+	}
+	if kind == Runtime && x.System {
+		return Explanation{Kind: kind, Path: x.home(kind), Source: SourceDefault}
+	}
+	if root, env := x.portableRoot(); root != "" {
+		return Explanation{Kind: kind, Path: x.home(kind), Source: SourcePortable, Env: env}
+	}
+	exp := Explain(kind)
+	exp.Path = x.home(kind)
+	return exp
+}
+
+// Explain reports which location is used for the home directory of kind and why
+func Explain(kind Kind) Explanation {
+	exp := Explanation{Kind: kind, Path: home(kind), Source: SourceDefault}
+	env := "XDG" + envSuffix(kind)
+	if os.Getenv(env) != "" {
+		exp.Source = SourceXDG
+		exp.Env = env
+	}
+	return exp
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type explainTestCase struct {
+	name        string
+	mokedMethod string
+	xdgEnv      string
+	xdgEnvVal   string
+	appEnv      string
+	appEnvVal   string
+	kind        Kind
+	expected    Explanation
+}
+
+var explainTestCases = []explainTestCase{
+	{"Default", "defaultConfigHome", "XDG_CONFIG_HOME", "", "FOO_CONFIG_HOME", "", Config, Explanation{Config, filepath.Clean("/default/OpenPeeDeeP/XDG"), SourceDefault, ""}},
+	{"XDG", "defaultConfigHome", "XDG_CONFIG_HOME", filepath.Clean("/xdg"), "FOO_CONFIG_HOME", "", Config, Explanation{Config, filepath.Clean("/xdg/OpenPeeDeeP/XDG"), SourceXDG, "XDG_CONFIG_HOME"}},
+	{"App Config", "defaultConfigHome", "XDG_CONFIG_HOME", filepath.Clean("/xdg"), "FOO_CONFIG_HOME", filepath.Clean("/app"), Config, Explanation{Config, filepath.Clean("/app"), SourceApp, "FOO_CONFIG_HOME"}},
+	{"App Data", "defaultDataHome", "XDG_DATA_HOME", "", "FOO_DATA_HOME", filepath.Clean("/app"), Data, Explanation{Data, filepath.Clean("/app"), SourceApp, "FOO_DATA_HOME"}},
+	{"App Cache", "defaultCacheHome", "XDG_CACHE_HOME", "", "FOO_CACHE_HOME", filepath.Clean("/app"), Cache, Explanation{Cache, filepath.Clean("/app"), SourceApp, "FOO_CACHE_HOME"}},
+	{"App State", "defaultStateHome", "XDG_STATE_HOME", "", "FOO_STATE_HOME", filepath.Clean("/app"), State, Explanation{State, filepath.Clean("/app"), SourceApp, "FOO_STATE_HOME"}},
+	{"XDG Runtime", "defaultStateHome", "XDG_RUNTIME_DIR", filepath.Clean("/run/user/1000"), "FOO_RUNTIME_DIR", "", Runtime, Explanation{Runtime, filepath.Clean("/run/user/1000/OpenPeeDeeP/XDG"), SourceXDG, "XDG_RUNTIME_DIR"}},
+	{"App Runtime", "defaultStateHome", "XDG_RUNTIME_DIR", "", "FOO_RUNTIME_DIR", filepath.Clean("/app"), Runtime, Explanation{Runtime, filepath.Clean("/app"), SourceApp, "FOO_RUNTIME_DIR"}},
+}
+
+func TestXDG_Explain(t *testing.T) {
+	for _, tc := range explainTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			mockDef := new(mockDefaulter)
+			mockDef.On(tc.mokedMethod).Return(filepath.Clean("/default"))
+			setDefaulter(mockDef)
+			os.Setenv(tc.xdgEnv, tc.xdgEnvVal) // nolint: errcheck
+			os.Setenv(tc.appEnv, tc.appEnvVal) // nolint: errcheck
+			defer os.Unsetenv(tc.appEnv)       // nolint: errcheck
+
+			x := New("OpenPeeDeeP", "XDG")
+			x.EnvPrefix = "FOO"
+			actual := x.Explain(tc.kind)
+			assert.Equal(tc.expected, actual)
+			assert.Equal(tc.expected.Path, x.home(tc.kind))
+		})
+	}
+}
+
+func TestXDG_EnvPrefixUnset(t *testing.T) {
+	assert := assert.New(t)
+	os.Setenv("XDG_CONFIG_HOME", filepath.Clean("/xdg")) // nolint: errcheck
+	os.Setenv("_CONFIG_HOME", filepath.Clean("/app"))    // nolint: errcheck
+	defer os.Unsetenv("_CONFIG_HOME")                    // nolint: errcheck
+
+	x := New("OpenPeeDeeP", "XDG")
+	assert.Equal(filepath.Clean("/xdg/OpenPeeDeeP/XDG"), x.ConfigHome())
+	assert.Equal(SourceXDG, x.Explain(Config).Source)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+)
+
+// DefaultHistorySize is the number of entries a History keeps when MaxEntries is 0
+const DefaultHistorySize = 1000
+
+// History is the list of commands entered in an interactive session, such as a REPL, kept in a file.
+// Every operation holds a lock, so concurrent sessions can share a history.
+type History struct {
+	Path string
+	// MaxEntries is the number of entries kept. The oldest entries are dropped from the file
+	// once there are more. DefaultHistorySize is used when it is 0.
+	MaxEntries int
+}
+
+// History returns the history kept in the file called name in StateHome
+func (x *XDG) History(name string) (*History, error) {
+	dir := x.StateHome()
+	if dir == "" {
+		return nil, ErrUnsupported
+	}
+	if !plainName(name) {
+		return nil, &UnsafeNameError{Name: name, Dir: dir}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &History{Path: filepath.Join(dir, name)}, nil
+}
+
+// Load returns the entries in the history, the oldest first.
+// A history that has not been written yet is empty.
+func (h *History) Load() ([]string, error) {
+	lock, err := lockfile.Lock(h.Path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock() // nolint: errcheck
+	return h.read()
+}
+
+// Append adds entries to the end of the history. An entry equal to the one before it is left out,
+// so a command repeated several times is kept once. Entries may span several lines.
+func (h *History) Append(entries ...string) error {
+	lock, err := lockfile.Lock(h.Path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock() // nolint: errcheck
+	existing, err := h.read()
+	if err != nil {
+		return err
+	}
+	var added []string
+	last, hasLast := "", len(existing) > 0
+	if hasLast {
+		last = existing[len(existing)-1]
+	}
+	for _, entry := range entries {
+		if hasLast && entry == last {
+			continue
+		}
+		added = append(added, entry)
+		last, hasLast = entry, true
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if all := append(existing, added...); len(all) > h.max() {
+		return h.write(all[len(all)-h.max():])
+	}
+	f, err := os.OpenFile(h.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(encodeHistory(added))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (h *History) max() int {
+	if h.MaxEntries <= 0 {
+		return DefaultHistorySize
+	}
+	return h.MaxEntries
+}
+
+func (h *History) read() ([]string, error) {
+	f, err := os.Open(h.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		entries = append(entries, unescapeHistory(scanner.Text()))
+	}
+	return entries, scanner.Err()
+}
+
+// write replaces the history file with entries atomically
+func (h *History) write(entries []string) error {
+	return atomicfile.WriteFile(h.Path, encodeHistory(entries), 0600)
+}
+
+// encodeHistory writes every entry on a line of its own, escaping line breaks and backslashes
+func encodeHistory(entries []string) []byte {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		buf.WriteString(historyEscaper.Replace(entry))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+var historyEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+func unescapeHistory(line string) string {
+	if !strings.Contains(line, `\`) {
+		return line
+	}
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if line[i] != '\\' || i == len(line)-1 {
+			b.WriteByte(line[i])
+			continue
+		}
+		i++
+		switch line[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(line[i])
+		}
+	}
+	return b.String()
+}
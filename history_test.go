@@ -0,0 +1,112 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func standupHistory(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_STATE_HOME", tmp) // nolint: errcheck
+	return filepath.Join(tmp, "OpenPeeDeeP", "XDG"), func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestXDG_History(t *testing.T) {
+	assert := assert.New(t)
+	dir, teardown := standupHistory(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	h, err := x.History("repl_history")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(filepath.Join(dir, "repl_history"), h.Path)
+	entries, err := h.Load()
+	assert.NoError(err)
+	assert.Empty(entries)
+
+	assert.NoError(h.Append("ls", "ls", "cd /tmp"))
+	assert.NoError(h.Append("cd /tmp", "for x in a b\ndo echo $x\ndone", `echo C:\Users`, ""))
+	assert.NoError(h.Append("ls"))
+	entries, err = h.Load()
+	assert.NoError(err)
+	assert.Equal([]string{"ls", "cd /tmp", "for x in a b\ndo echo $x\ndone", `echo C:\Users`, "", "ls"}, entries)
+	content, err := ioutil.ReadFile(h.Path)
+	assert.NoError(err)
+	assert.Equal("ls\ncd /tmp\nfor x in a b\\ndo echo $x\\ndone\necho C:\\\\Users\n\nls\n", string(content))
+
+	_, err = x.History("../repl_history")
+	assert.IsType(&UnsafeNameError{}, err)
+}
+
+func TestHistory_MaxEntries(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupHistory(t)
+	defer teardown()
+	h, err := New("OpenPeeDeeP", "XDG").History("history")
+	if !assert.NoError(err) {
+		return
+	}
+	h.MaxEntries = 3
+	assert.NoError(h.Append("a", "b"))
+	assert.NoError(h.Append("c", "d", "e"))
+	entries, err := h.Load()
+	assert.NoError(err)
+	assert.Equal([]string{"c", "d", "e"}, entries)
+	assert.NoError(h.Append("f"))
+	entries, err = h.Load()
+	assert.NoError(err)
+	assert.Equal([]string{"d", "e", "f"}, entries)
+}
+
+func TestHistory_Concurrent(t *testing.T) {
+	// fcntl locks are held by the process, so they do not keep sessions of the same process apart
+	if !lockfile.Supported || runtime.GOOS == "aix" || runtime.GOOS == "solaris" {
+		t.Skip("file locks are not shared between open files")
+	}
+	assert := assert.New(t)
+	_, teardown := standupHistory(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	const sessions, commands = 4, 25
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		h, err := x.History("history")
+		if !assert.NoError(err) {
+			return
+		}
+		h.MaxEntries = sessions * commands
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < commands; j++ {
+				h.Append(fmt.Sprintf("session %d command %d", i, j)) // nolint: errcheck
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	h, _ := x.History("history")
+	entries, err := h.Load()
+	assert.NoError(err)
+	assert.Len(entries, sessions*commands)
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package icontheme finds the file of an icon by name, size and scale in the installed icon themes.
+//
+// See https://specifications.freedesktop.org/icon-theme-spec/latest/ for the lookup algorithm.
+package icontheme
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg"
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+)
+
+// Hicolor is the theme every theme falls back to
+const Hicolor = "hicolor"
+
+const themeGroup = "Icon Theme"
+
+// Extensions are the file extensions of icons, the preferred first
+var Extensions = []string{".png", ".svg", ".xpm"}
+
+// Dirs returns the base directories holding icon themes and fallback icons, the most important first
+func Dirs() []string {
+	var dirs []string
+	if home := os.Getenv("HOME"); home != "" {
+		dirs = append(dirs, filepath.Join(home, ".icons"))
+	}
+	for _, dir := range xdg.SearchDirs(xdg.Data) {
+		dirs = append(dirs, filepath.Join(dir, "icons"))
+	}
+	return append(dirs, filepath.Join(string(filepath.Separator), "usr", "share", "pixmaps"))
+}
+
+// The types of icon directories
+const (
+	Fixed     = "Fixed"
+	Scalable  = "Scalable"
+	Threshold = "Threshold"
+)
+
+// Directory is a subdirectory of a theme holding icons of one size
+type Directory struct {
+	Path      string
+	Size      int
+	Scale     int
+	MinSize   int
+	MaxSize   int
+	Threshold int
+	Type      string
+	Context   string
+}
+
+// Theme is an icon theme described by an index.theme file
+type Theme struct {
+	// Name is the name of the theme directory
+	Name        string
+	DisplayName string
+	Inherits    []string
+	Directories []Directory
+	Hidden      bool
+	// Dirs are the directories of the theme in the base directories
+	Dirs []string
+}
+
+// LoadTheme reads the index.theme of the named theme from the first base directory holding one.
+// Returns an error satisfying os.IsNotExist when the theme is not installed.
+func LoadTheme(name string, baseDirs []string) (*Theme, error) {
+	t := &Theme{Name: name}
+	var index *desktopentry.File
+	for _, dir := range baseDirs {
+		themeDir := filepath.Join(dir, name)
+		if info, err := os.Stat(themeDir); err != nil || !info.IsDir() {
+			continue
+		}
+		t.Dirs = append(t.Dirs, themeDir)
+		if index != nil {
+			continue
+		}
+		f, err := desktopentry.ParseFile(filepath.Join(themeDir, "index.theme"))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		index = f
+	}
+	if index == nil || index.Group(themeGroup) == nil {
+		return nil, &os.PathError{Op: "load", Path: filepath.Join(name, "index.theme"), Err: os.ErrNotExist}
+	}
+	main := index.Group(themeGroup)
+	t.DisplayName = main.LocaleString("Name", desktopentry.Locale())
+	t.Inherits = splitComma(main.String("Inherits"))
+	t.Hidden = main.Bool("Hidden")
+	for _, path := range append(splitComma(main.String("Directories")), splitComma(main.String("ScaledDirectories"))...) {
+		g := index.Group(path)
+		if g == nil {
+			continue
+		}
+		d := Directory{
+			Path:      path,
+			Size:      integer(g, "Size", 0),
+			Scale:     integer(g, "Scale", 1),
+			Threshold: integer(g, "Threshold", 2),
+			Type:      g.String("Type"),
+			Context:   g.String("Context"),
+		}
+		if d.Size <= 0 {
+			continue
+		}
+		if d.Type == "" {
+			d.Type = Threshold
+		}
+		d.MinSize = integer(g, "MinSize", d.Size)
+		d.MaxSize = integer(g, "MaxSize", d.Size)
+		t.Directories = append(t.Directories, d)
+	}
+	return t, nil
+}
+
+func splitComma(value string) []string {
+	var parts []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func integer(g *desktopentry.Group, key string, def int) int {
+	n, err := strconv.Atoi(g.String(key))
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// matches reports whether icons in the directory can be used as is for the size and scale
+func (d Directory) matches(size, scale int) bool {
+	if d.Scale != scale {
+		return false
+	}
+	switch d.Type {
+	case Fixed:
+		return d.Size == size
+	case Scalable:
+		return d.MinSize <= size && size <= d.MaxSize
+	default:
+		return d.Size-d.Threshold <= size && size <= d.Size+d.Threshold
+	}
+}
+
+// distance returns how far icons in the directory are from the size and scale in pixels
+func (d Directory) distance(size, scale int) int {
+	want := size * scale
+	min, max := d.Size, d.Size
+	switch d.Type {
+	case Scalable:
+		min, max = d.MinSize, d.MaxSize
+	case Threshold:
+		min, max = d.Size-d.Threshold, d.Size+d.Threshold
+	}
+	switch {
+	case want < min*d.Scale:
+		return min*d.Scale - want
+	case want > max*d.Scale:
+		return want - max*d.Scale
+	}
+	return 0
+}
+
+// Finder looks up icons, caching the themes it reads
+type Finder struct {
+	// Theme is the theme chosen by the user, hicolor is used when it is empty
+	Theme string
+	// Context restricts the lookup to directories of the context, such as Applications, when it is set
+	Context string
+	// BaseDirs are the base directories searched, Dirs by default
+	BaseDirs []string
+
+	themes map[string]*Theme
+}
+
+// NewFinder returns a Finder for the named theme searching Dirs
+func NewFinder(theme string) *Finder {
+	return &Finder{Theme: theme, BaseDirs: Dirs()}
+}
+
+// Find returns the path of the named icon for the user's theme at the given size and scale.
+// The theme and the themes it inherits from are searched first, then hicolor and
+// finally the icons directly in the base directories, such as /usr/share/pixmaps.
+// An exact match is preferred over the icon closest in size. Returns an empty string when there is no such icon.
+func (f *Finder) Find(icon string, size, scale int) string {
+	if scale < 1 {
+		scale = 1
+	}
+	if icon == "" || strings.ContainsAny(icon, `/\`) {
+		return ""
+	}
+	theme := f.Theme
+	if theme == "" {
+		theme = Hicolor
+	}
+	if path := f.findInTheme(icon, size, scale, theme, make(map[string]bool)); path != "" {
+		return path
+	}
+	if path := f.findInTheme(icon, size, scale, Hicolor, make(map[string]bool)); path != "" {
+		return path
+	}
+	for _, dir := range f.BaseDirs {
+		if path := lookupFile(dir, icon); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// Find returns the path of the named icon in theme, see Finder.Find
+func Find(theme, icon string, size, scale int) string {
+	return NewFinder(theme).Find(icon, size, scale)
+}
+
+func (f *Finder) findInTheme(icon string, size, scale int, name string, visited map[string]bool) string {
+	if visited[name] {
+		return ""
+	}
+	visited[name] = true
+	t := f.theme(name)
+	if t == nil {
+		return ""
+	}
+	if path := f.lookup(t, icon, size, scale); path != "" {
+		return path
+	}
+	for _, parent := range t.Inherits {
+		if path := f.findInTheme(icon, size, scale, parent, visited); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+func (f *Finder) theme(name string) *Theme {
+	if t, ok := f.themes[name]; ok {
+		return t
+	}
+	if f.themes == nil {
+		f.themes = make(map[string]*Theme)
+	}
+	t, err := LoadTheme(name, f.BaseDirs)
+	if err != nil {
+		t = nil
+	}
+	f.themes[name] = t
+	return t
+}
+
+// lookup returns the icon in the directory of t matching size and scale,
+// or else the one in the directory closest to it
+func (f *Finder) lookup(t *Theme, icon string, size, scale int) string {
+	closest, best := "", -1
+	for _, d := range t.Directories {
+		if f.Context != "" && d.Context != f.Context {
+			continue
+		}
+		exact := d.matches(size, scale)
+		distance := d.distance(size, scale)
+		if !exact && best >= 0 && distance >= best {
+			continue
+		}
+		for _, dir := range t.Dirs {
+			path := lookupFile(filepath.Join(dir, d.Path), icon)
+			if path == "" {
+				continue
+			}
+			if exact {
+				return path
+			}
+			closest, best = path, distance
+			break
+		}
+	}
+	return closest
+}
+
+func lookupFile(dir, icon string) string {
+	for _, ext := range Extensions {
+		path := filepath.Join(dir, icon+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
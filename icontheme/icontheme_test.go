@@ -0,0 +1,179 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package icontheme
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const customIndex = `[Icon Theme]
+Name=Custom
+Name[de]=Eigenes
+Inherits=Base, Custom
+Directories=16x16/apps,48x48/apps,scalable/apps,16x16/actions
+ScaledDirectories=16x16@2/apps
+
+[16x16/apps]
+Size=16
+Context=Applications
+Type=Fixed
+
+[16x16@2/apps]
+Size=16
+Scale=2
+Context=Applications
+Type=Fixed
+
+[48x48/apps]
+Size=48
+Context=Applications
+
+[scalable/apps]
+Size=64
+MinSize=64
+MaxSize=256
+Context=Applications
+Type=Scalable
+
+[16x16/actions]
+Size=16
+Context=Actions
+Type=Fixed
+`
+
+const baseIndex = `[Icon Theme]
+Name=Base
+Directories=22x22/apps
+
+[22x22/apps]
+Size=22
+Type=Fixed
+`
+
+const hicolorIndex = `[Icon Theme]
+Name=Hicolor
+Directories=32x32/apps
+
+[32x32/apps]
+Size=32
+Type=Threshold
+`
+
+func standupThemes(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "icontheme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("HOME", filepath.Join(tmp, "home"))                   // nolint: errcheck
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "home", "share")) // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmp, "usr", "share"))  // nolint: errcheck
+	for path, content := range map[string]string{
+		"home/share/icons/Custom/index.theme":              customIndex,
+		"home/share/icons/Custom/16x16/apps/editor.png":    "",
+		"home/share/icons/Custom/16x16@2/apps/editor.png":  "",
+		"home/share/icons/Custom/48x48/apps/editor.svg":    "",
+		"home/share/icons/Custom/16x16/actions/search.png": "",
+		"home/.icons/Custom/scalable/apps/editor.svg":      "",
+		"home/.icons/Custom/scalable/apps/player.svg":      "",
+		"usr/share/icons/Base/index.theme":                 baseIndex,
+		"usr/share/icons/Base/22x22/apps/terminal.png":     "",
+		"usr/share/icons/hicolor/index.theme":              hicolorIndex,
+		"usr/share/icons/hicolor/32x32/apps/browser.png":   "",
+		"usr/share/icons/hicolor/32x32/apps/player.xpm":    "",
+		"pixmaps/legacy.xpm":                               "",
+	} {
+		path = filepath.Join(tmp, filepath.FromSlash(path))
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestDirs(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupThemes(t)
+	defer teardown()
+	assert.Equal([]string{
+		filepath.Join(tmp, "home", ".icons"),
+		filepath.Join(tmp, "home", "share", "icons"),
+		filepath.Join(tmp, "usr", "share", "icons"),
+		filepath.Join(string(filepath.Separator), "usr", "share", "pixmaps"),
+	}, Dirs())
+}
+
+func TestLoadTheme(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupThemes(t)
+	defer teardown()
+	theme, err := LoadTheme("Custom", Dirs())
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("Custom", theme.DisplayName)
+	assert.Equal([]string{"Base", "Custom"}, theme.Inherits)
+	assert.Equal([]string{filepath.Join(tmp, "home", ".icons", "Custom"), filepath.Join(tmp, "home", "share", "icons", "Custom")}, theme.Dirs)
+	if assert.Len(theme.Directories, 5) {
+		assert.Equal(Directory{Path: "48x48/apps", Size: 48, Scale: 1, MinSize: 48, MaxSize: 48, Threshold: 2, Type: Threshold, Context: "Applications"}, theme.Directories[1])
+		assert.Equal(2, theme.Directories[4].Scale)
+	}
+
+	_, err = LoadTheme("Missing", Dirs())
+	assert.True(os.IsNotExist(err))
+}
+
+func TestFind(t *testing.T) {
+	tmp, teardown := standupThemes(t)
+	defer teardown()
+	tests := []struct {
+		name     string
+		theme    string
+		context  string
+		icon     string
+		size     int
+		scale    int
+		expected string
+	}{
+		{"Exact", "Custom", "", "editor", 16, 1, "home/share/icons/Custom/16x16/apps/editor.png"},
+		{"Scaled", "Custom", "", "editor", 16, 2, "home/share/icons/Custom/16x16@2/apps/editor.png"},
+		{"Threshold", "Custom", "", "editor", 50, 1, "home/share/icons/Custom/48x48/apps/editor.svg"},
+		{"Scalable", "Custom", "", "editor", 128, 1, "home/.icons/Custom/scalable/apps/editor.svg"},
+		{"Closest", "Custom", "", "editor", 24, 1, "home/share/icons/Custom/16x16/apps/editor.png"},
+		{"ClosestLarge", "Custom", "", "editor", 512, 1, "home/.icons/Custom/scalable/apps/editor.svg"},
+		{"Inherited", "Custom", "", "terminal", 48, 1, "usr/share/icons/Base/22x22/apps/terminal.png"},
+		{"Hicolor", "Custom", "", "browser", 32, 1, "usr/share/icons/hicolor/32x32/apps/browser.png"},
+		{"ThemeBeforeHicolor", "Custom", "", "player", 32, 1, "home/.icons/Custom/scalable/apps/player.svg"},
+		{"DefaultTheme", "", "", "player", 32, 1, "usr/share/icons/hicolor/32x32/apps/player.xpm"},
+		{"MissingTheme", "Missing", "", "browser", 16, 1, "usr/share/icons/hicolor/32x32/apps/browser.png"},
+		{"Context", "Custom", "Actions", "search", 16, 1, "home/share/icons/Custom/16x16/actions/search.png"},
+		{"OtherContext", "Custom", "Actions", "editor", 16, 1, ""},
+		{"Pixmaps", "Custom", "", "legacy", 16, 1, "pixmaps/legacy.xpm"},
+		{"Missing", "Custom", "", "missing", 16, 1, ""},
+		{"Path", "Custom", "", "../Custom/16x16/apps/editor", 16, 1, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			f := NewFinder(test.theme)
+			f.Context = test.context
+			f.BaseDirs = append(f.BaseDirs[:len(f.BaseDirs)-1], filepath.Join(tmp, "pixmaps"))
+			expected := ""
+			if test.expected != "" {
+				expected = filepath.Join(tmp, filepath.FromSlash(test.expected))
+			}
+			assert.Equal(expected, f.Find(test.icon, test.size, test.scale))
+		})
+	}
+}
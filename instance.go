@@ -0,0 +1,146 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+)
+
+// InstanceLockName is the name of the lock file in the application's runtime directory
+const InstanceLockName = "instance.lock"
+
+// AlreadyRunningError is returned when another instance of the application holds the instance lock
+type AlreadyRunningError struct {
+	Path string
+	// PID is the process holding the lock, or 0 when it could not be read
+	PID int
+}
+
+func (e *AlreadyRunningError) Error() string {
+	if e.PID == 0 {
+		return "xdg: already running, " + e.Path + " is locked"
+	}
+	return "xdg: already running as process " + strconv.Itoa(e.PID) + ", " + e.Path + " is locked"
+}
+
+// InstanceLock is held by the only running instance of an application
+type InstanceLock struct {
+	Path string
+	// StalePID is the process that left the lock file behind without releasing it, or 0
+	StalePID int
+
+	file *lockfile.File
+}
+
+// AcquireInstanceLock makes sure only one instance of the application runs for the user.
+// It locks a file in RuntimeDir holding the PID of the running instance, or returns an
+// *AlreadyRunningError when another process holds the lock. The lock of a process that died
+// is released by the operating system, so the lock file it left behind is taken over.
+func (x *XDG) AcquireInstanceLock() (*InstanceLock, error) {
+	dir, err := x.RuntimeDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, InstanceLockName)
+	for {
+		f, err := lockfile.TryLock(path)
+		if err == lockfile.ErrLocked {
+			return nil, &AlreadyRunningError{Path: path, PID: readPID(path)}
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The previous holder may have removed the file between our open and lock
+		if !lockedPath(f, path) {
+			f.Unlock() // nolint: errcheck
+			continue
+		}
+		l := &InstanceLock{Path: path, file: f}
+		// Where locks are fcntl record locks, closing any other descriptor of the file would release it
+		if pid := readLockedPID(f.File); pid != 0 && pid != os.Getpid() {
+			if !lockfile.Supported && processAlive(pid) {
+				f.Unlock() // nolint: errcheck
+				return nil, &AlreadyRunningError{Path: path, PID: pid}
+			}
+			l.StalePID = pid
+		}
+		if err = writePID(f.File); err != nil {
+			f.Unlock() // nolint: errcheck
+			return nil, err
+		}
+		return l, nil
+	}
+}
+
+// Release removes the lock file and releases the lock.
+// Where open files can not be removed, the file is emptied instead.
+func (l *InstanceLock) Release() error {
+	err := os.Remove(l.Path)
+	switch {
+	case os.IsNotExist(err):
+		err = nil
+	case err != nil:
+		err = l.file.Truncate(0)
+	}
+	if unlockErr := l.file.Unlock(); err == nil {
+		err = unlockErr
+	}
+	return err
+}
+
+// lockedPath reports whether path still names the locked file
+func lockedPath(f *lockfile.File, path string) bool {
+	locked, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(locked, current)
+}
+
+// readPID returns the PID written at the start of the file at path, or 0
+func readPID(path string) int {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	return parsePID(content)
+}
+
+// readLockedPID returns the PID written at the start of the locked file f, or 0
+func readLockedPID(f *os.File) int {
+	content := make([]byte, 32)
+	n, err := f.ReadAt(content, 0)
+	if err != nil && err != io.EOF {
+		return 0
+	}
+	return parsePID(content[:n])
+}
+
+func parsePID(content []byte) int {
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid < 0 {
+		return 0
+	}
+	return pid
+}
+
+// writePID replaces the content of f with the PID of the running process
+func writePID(f *os.File) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
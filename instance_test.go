@@ -0,0 +1,76 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXDG_AcquireInstanceLock(t *testing.T) {
+	if !lockfile.Supported {
+		t.Skip("file locks are not supported")
+	}
+	if runtime.GOOS == "aix" || runtime.GOOS == "solaris" || runtime.GOOS == "illumos" {
+		t.Skip("fcntl locks do not exclude the process holding them")
+	}
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	path := filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG", InstanceLockName)
+
+	l, err := x.AcquireInstanceLock()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(path, l.Path)
+	assert.Equal(0, l.StalePID)
+	assert.Equal(os.Getpid(), readLockedPID(l.file.File))
+
+	_, err = x.AcquireInstanceLock()
+	assert.Equal(&AlreadyRunningError{Path: path, PID: os.Getpid()}, err)
+	assert.NoError(l.Release())
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+
+	l, err = x.AcquireInstanceLock()
+	if assert.NoError(err) {
+		assert.NoError(l.Release())
+	}
+}
+
+func TestXDG_AcquireInstanceLockStale(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	dir := filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG")
+	assert.NoError(os.MkdirAll(dir, 0700))
+	// Far above the PID limit of any platform, so no process has it
+	stale := 1 << 30
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, InstanceLockName), []byte(strconv.Itoa(stale)+"\n"), 0600))
+
+	l, err := x.AcquireInstanceLock()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(stale, l.StalePID)
+	assert.Equal(os.Getpid(), readLockedPID(l.file.File))
+	assert.NoError(l.Release())
+}
+
+func TestAlreadyRunningError(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("xdg: already running as process 42, /run/app.lock is locked", (&AlreadyRunningError{Path: "/run/app.lock", PID: 42}).Error())
+	assert.Equal("xdg: already running, /run/app.lock is locked", (&AlreadyRunningError{Path: "/run/app.lock"}).Error())
+}
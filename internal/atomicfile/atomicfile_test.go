@@ -0,0 +1,55 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package atomicfile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	assert := assert.New(t)
+	tmp, err := ioutil.TempDir("", "atomicfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	path := filepath.Join(tmp, "file.txt")
+
+	assert.NoError(WriteFile(path, []byte("old"), 0640))
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("old", string(content))
+	if runtime.GOOS != "windows" && runtime.GOOS != "plan9" {
+		info, err := os.Stat(path)
+		if assert.NoError(err) {
+			assert.Equal(os.FileMode(0640), info.Mode().Perm())
+		}
+	}
+
+	failed := errors.New("failed")
+	assert.Equal(failed, Write(path, 0600, func(w io.Writer) error {
+		io.WriteString(w, "partial") // nolint: errcheck
+		return failed
+	}))
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("old", string(content))
+
+	assert.NoError(WriteFile(path, []byte("new"), 0600))
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("new", string(content))
+	entries, err := ioutil.ReadDir(tmp)
+	assert.NoError(err)
+	assert.Len(entries, 1)
+}
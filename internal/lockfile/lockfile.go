@@ -0,0 +1,50 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lockfile holds exclusive locks on files shared between processes
+package lockfile
+
+import (
+	"errors"
+	"os"
+)
+
+// ErrLocked is returned by TryLock when another process holds the lock
+var ErrLocked = errors.New("lockfile: locked by another process")
+
+// File is an open file the process holds an exclusive lock on
+type File struct {
+	*os.File
+}
+
+// Lock opens path, creating it when it is missing, and blocks until the process holds an exclusive lock on it
+func Lock(path string) (*File, error) {
+	return open(path, true)
+}
+
+// TryLock is like Lock but returns ErrLocked instead of waiting for another process to release the lock
+func TryLock(path string) (*File, error) {
+	return open(path, false)
+}
+
+func open(path string, block bool) (*File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err = lock(f, block); err != nil {
+		f.Close() // nolint: errcheck
+		return nil, err
+	}
+	return &File{f}, nil
+}
+
+// Unlock releases the lock and closes the file
+func (f *File) Unlock() error {
+	err := unlock(f.File)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+// +build aix solaris
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lockfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// Supported reports whether locks exclude other processes on this platform
+const Supported = true
+
+// lock takes a POSIX record lock on the whole file.
+// Unlike flock, these locks belong to the process, so they only exclude other processes.
+func lock(f *os.File, block bool) error {
+	cmd := syscall.F_SETLKW
+	if !block {
+		cmd = syscall.F_SETLK
+	}
+	lk := syscall.Flock_t{Type: syscall.F_WRLCK}
+	for {
+		err := syscall.FcntlFlock(f.Fd(), cmd, &lk)
+		switch err {
+		case syscall.EINTR:
+			continue
+		case syscall.EAGAIN, syscall.EACCES:
+			return ErrLocked
+		}
+		return err
+	}
+}
+
+func unlock(f *os.File) error {
+	lk := syscall.Flock_t{Type: syscall.F_UNLCK}
+	return syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, &lk)
+}
@@ -0,0 +1,21 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!aix,!solaris,!windows
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lockfile
+
+import "os"
+
+// Supported reports whether locks exclude other processes on this platform
+const Supported = false
+
+// lock does nothing where the platform has no advisory file locks
+func lock(f *os.File, block bool) error {
+	return nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
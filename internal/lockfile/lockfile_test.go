@@ -0,0 +1,47 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lockfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	switch runtime.GOOS {
+	case "aix", "solaris", "illumos", "plan9", "js", "wasip1":
+		t.Skip("locks do not exclude the process holding them on", runtime.GOOS)
+	}
+	assert := assert.New(t)
+	tmp, err := ioutil.TempDir("", "lockfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	path := filepath.Join(tmp, "test.lock")
+
+	f, err := Lock(path)
+	if !assert.NoError(err) {
+		return
+	}
+	_, err = f.WriteString("held")
+	assert.NoError(err)
+	_, err = TryLock(path)
+	assert.Equal(ErrLocked, err)
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal("held", string(content))
+	assert.NoError(f.Unlock())
+
+	f, err = TryLock(path)
+	if assert.NoError(err) {
+		assert.NoError(f.Unlock())
+	}
+}
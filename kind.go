@@ -0,0 +1,94 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+)
+
+// CustomKind is a category of files an application keeps in a subdirectory of the directories of a base kind,
+// such as themes in the data directories
+type CustomKind struct {
+	Name    string
+	Base    Kind
+	Subpath string
+
+	x *XDG
+}
+
+// RegisterKind adds the category called name, kept in subpath of the application's directories of base.
+// For example RegisterKind("themes", Data, "themes") keeps themes in DataHome()/themes and every DataDirs()/themes.
+// Registering a name twice is an error.
+func (x *XDG) RegisterKind(name string, base Kind, subpath string) (*CustomKind, error) {
+	if name == "" {
+		return nil, errors.New("xdg: a kind needs a name")
+	}
+	if base < 0 || int(base) >= len(kindNames) {
+		return nil, errors.New("xdg: unknown base kind " + base.String())
+	}
+	if subpath == "" || escapes(subpath) {
+		return nil, &UnsafeNameError{Name: subpath, Dir: x.home(base)}
+	}
+	if _, ok := x.kinds[name]; ok {
+		return nil, errors.New("xdg: kind " + strconv.Quote(name) + " is already registered")
+	}
+	if x.kinds == nil {
+		x.kinds = make(map[string]*CustomKind)
+	}
+	k := &CustomKind{Name: name, Base: base, Subpath: subpath, x: x}
+	x.kinds[name] = k
+	return k, nil
+}
+
+// Kind returns the category registered as name, or nil when there is none
+func (x *XDG) Kind(name string) *CustomKind {
+	return x.kinds[name]
+}
+
+// Home returns the location of user specific files of the kind.
+// Returns an empty string when the base kind has no home directory.
+func (k *CustomKind) Home() string {
+	home := k.x.home(k.Base)
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, k.Subpath)
+}
+
+// Dirs returns the locations of system wide files of the kind
+func (k *CustomKind) Dirs() []string {
+	dirs := k.x.dirs(k.Base)
+	for i, dir := range dirs {
+		dirs[i] = filepath.Join(dir, k.Subpath)
+	}
+	return dirs
+}
+
+// SearchDirs returns the locations searched for files of the kind.
+// The home directory comes first followed by the system wide directories, without duplicates.
+func (k *CustomKind) SearchDirs() []string {
+	return cleanDirs(append([]string{k.Home()}, k.Dirs()...))
+}
+
+// Query looks for the given filename in the directories of the kind, like (*XDG).Query.
+// Returns an empty string if one was not found.
+func (k *CustomKind) Query(filename string) (string, error) {
+	dirs := k.SearchDirs()
+	if len(dirs) == 0 {
+		return "", ErrUnsupported
+	}
+	return k.x.lookup(k.Base, filename, dirs)
+}
+
+// QueryAll returns every path of the given filename in the directories of the kind, the most important first
+func (k *CustomKind) QueryAll(filename string) ([]string, error) {
+	dirs := k.SearchDirs()
+	if len(dirs) == 0 {
+		return nil, ErrUnsupported
+	}
+	return k.x.lookupAll(k.Base, filename, dirs)
+}
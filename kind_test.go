@@ -0,0 +1,122 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupKinds(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-kinds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataDirs := strings.Join([]string{filepath.Join(tmp, "local"), filepath.Join(tmp, "share")}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "home"))   // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                     // nolint: errcheck
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(tmp, "cache")) // nolint: errcheck
+	for _, base := range []string{"home", "share"} {
+		dir := filepath.Join(tmp, base, "OpenPeeDeeP", "XDG", "themes")
+		if err = os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, "dark.css"), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestXDG_RegisterKind(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupKinds(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	themes, err := x.RegisterKind("themes", Data, "themes")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(themes, x.Kind("themes"))
+	assert.Nil(x.Kind("models"))
+	assert.Equal(filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG", "themes"), themes.Home())
+	assert.Equal([]string{
+		filepath.Join(tmp, "local", "OpenPeeDeeP", "XDG", "themes"),
+		filepath.Join(tmp, "share", "OpenPeeDeeP", "XDG", "themes"),
+	}, themes.Dirs())
+	assert.Equal(append([]string{themes.Home()}, themes.Dirs()...), themes.SearchDirs())
+
+	path, err := themes.Query("dark.css")
+	assert.NoError(err)
+	assert.Equal(filepath.Join(themes.Home(), "dark.css"), path)
+	path, err = themes.Query("light.css")
+	assert.NoError(err)
+	assert.Equal("", path)
+	paths, err := themes.QueryAll("dark.css")
+	assert.NoError(err)
+	assert.Equal([]string{filepath.Join(themes.Home(), "dark.css"), filepath.Join(themes.Dirs()[1], "dark.css")}, paths)
+
+	models, err := x.RegisterKind("models", Cache, filepath.Join("ml", "models"))
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(filepath.Join(tmp, "cache", "OpenPeeDeeP", "XDG", "ml", "models"), models.Home())
+	assert.Empty(models.Dirs())
+
+	x.SafeNames = true
+	_, err = themes.Query(filepath.Join("..", "themes", "dark.css"))
+	assert.IsType(&UnsafeNameError{}, err)
+	_, err = themes.QueryAll(filepath.Join("..", "themes", "dark.css"))
+	assert.IsType(&UnsafeNameError{}, err)
+}
+
+func TestXDG_RegisterKindErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		base    Kind
+		subpath string
+	}{
+		{"Duplicate", "themes", Data, "other"},
+		{"No Name", "", Data, "snippets"},
+		{"Unknown Base", "snippets", Kind(42), "snippets"},
+		{"No Subpath", "snippets", Data, ""},
+		{"Escaping Subpath", "snippets", Data, filepath.Join("..", "snippets")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := New("OpenPeeDeeP", "XDG")
+			x.RegisterKind("themes", Data, "themes") // nolint: errcheck
+			k, err := x.RegisterKind(tt.kind, tt.base, tt.subpath)
+			assert.Nil(t, k)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestXDG_QueryAll(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupKinds(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	paths, err := x.QueryAll(Data, filepath.Join("themes", "dark.css"))
+	assert.NoError(err)
+	assert.Equal([]string{
+		filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG", "themes", "dark.css"),
+		filepath.Join(tmp, "share", "OpenPeeDeeP", "XDG", "themes", "dark.css"),
+	}, paths)
+	paths, err = x.QueryAll(Data, "missing.css")
+	assert.NoError(err)
+	assert.Empty(paths)
+}
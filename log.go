@@ -0,0 +1,284 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+)
+
+// Defaults used for the zero fields of LogRotation
+const (
+	DefaultLogMaxSize  = 10 << 20
+	DefaultLogMaxTotal = 100 << 20
+)
+
+// logStamp names rotated segments so they sort by the time they were rotated
+const logStamp = "20060102T150405.000000000Z"
+
+// LogRotation controls when LogWriter starts a new log file and how many old ones are kept
+type LogRotation struct {
+	// MaxSize is the size in bytes a log file grows to before it is rotated. DefaultLogMaxSize is used when it is 0.
+	MaxSize int64
+	// MaxAge is how long a log file is written to before it is rotated. Files are not rotated by age when it is 0.
+	MaxAge time.Duration
+	// MaxTotal is the number of bytes kept in rotated segments. The oldest are removed first.
+	// DefaultLogMaxTotal is used when it is 0.
+	MaxTotal int64
+}
+
+// LogDir returns the directory in StateHome holding the application's logs
+func (x *XDG) LogDir() string {
+	dir := x.StateHome()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "logs")
+}
+
+// LogWriter returns a writer appending to name.log in LogDir, such as LogWriter("app") for app.log.
+// The file is rotated as configured by LogRotation: it is renamed to name-<time>.log, compressed
+// to name-<time>.log.gz and the oldest segments are removed. Every write holds a lock shared by
+// all the processes of the application, so several processes can log to the same file.
+func (x *XDG) LogWriter(name string) (io.WriteCloser, error) {
+	dir := x.LogDir()
+	if dir == "" {
+		return nil, ErrUnsupported
+	}
+	if !plainName(name + ".log") {
+		return nil, &UnsafeNameError{Name: name, Dir: dir}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	w := &logWriter{
+		dir:      dir,
+		name:     name,
+		path:     filepath.Join(dir, name+".log"),
+		rotation: x.LogRotation,
+	}
+	if w.rotation.MaxSize <= 0 {
+		w.rotation.MaxSize = DefaultLogMaxSize
+	}
+	if w.rotation.MaxTotal <= 0 {
+		w.rotation.MaxTotal = DefaultLogMaxTotal
+	}
+	return w, nil
+}
+
+type logWriter struct {
+	dir      string
+	name     string
+	path     string
+	rotation LogRotation
+
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+}
+
+// Write appends p to the log file, rotating it first when p would make it too large or it is too old
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	// The lock file is kept beside the log file. Its modification time records when the log file was started.
+	lock, err := lockfile.Lock(w.path + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	n, rotated, err := w.write(lock, p)
+	lock.Unlock() // nolint: errcheck
+	if rotated {
+		// Other processes go on logging while the segments are compressed
+		w.compress()
+	}
+	return n, err
+}
+
+// write appends p to the log file while holding lock and reports whether the file was rotated
+func (w *logWriter) write(lock *lockfile.File, p []byte) (int, bool, error) {
+	if err := w.open(lock); err != nil {
+		return 0, false, err
+	}
+	rotated := false
+	if w.due(lock, int64(len(p))) {
+		rotated = w.rotate()
+		if err := w.open(lock); err != nil {
+			return 0, rotated, err
+		}
+	}
+	n, err := w.file.Write(p)
+	return n, rotated, err
+}
+
+// Close closes the log file. Writes after Close fail.
+func (w *logWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// open makes sure w.file is the file at w.path, which another process may have rotated
+func (w *logWriter) open(lock *lockfile.File) error {
+	if w.file != nil {
+		current, err := w.file.Stat()
+		if err == nil {
+			if info, err := os.Stat(w.path); err == nil && os.SameFile(current, info) {
+				return nil
+			}
+		}
+		w.file.Close() // nolint: errcheck
+		w.file = nil
+	}
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0600)
+	if err == nil {
+		now := time.Now()
+		os.Chtimes(lock.Name(), now, now) // nolint: errcheck
+	} else if os.IsExist(err) {
+		f, err = os.OpenFile(w.path, os.O_WRONLY|os.O_APPEND, 0600)
+	}
+	if err != nil {
+		return err
+	}
+	w.file = f
+	return nil
+}
+
+// due reports whether the log file must be rotated before n more bytes are written to it
+func (w *logWriter) due(lock *lockfile.File, n int64) bool {
+	info, err := w.file.Stat()
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+	if info.Size()+n > w.rotation.MaxSize {
+		return true
+	}
+	if w.rotation.MaxAge <= 0 {
+		return false
+	}
+	started, err := lock.Stat()
+	return err == nil && time.Since(started.ModTime()) >= w.rotation.MaxAge
+}
+
+// rotate moves the log file aside and reports whether it did. Windows can not rename
+// a file another process has open, in which case the file keeps growing until it can.
+func (w *logWriter) rotate() bool {
+	w.file.Close() // nolint: errcheck
+	w.file = nil
+	segment := filepath.Join(w.dir, w.name+"-"+time.Now().UTC().Format(logStamp)+".log")
+	return os.Rename(w.path, segment) == nil
+}
+
+// compress compresses and prunes the rotated segments. Several processes may do so at once,
+// and failures are left for the next rotation to retry, so logging goes on.
+func (w *logWriter) compress() {
+	segments, err := w.segments()
+	if err != nil {
+		return
+	}
+	for i, s := range segments {
+		if filepath.Ext(s) == ".log" {
+			if compressed, err := compressLog(s); err == nil {
+				segments[i] = compressed
+			}
+		}
+	}
+	w.prune(segments)
+}
+
+// segments returns the paths of the rotated segments, the newest first.
+// A segment another process has compressed but not removed yet is left out.
+func (w *logWriter) segments() ([]string, error) {
+	dir, err := os.Open(w.dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := dir.Readdirnames(-1)
+	dir.Close() // nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	compressed := make(map[string]bool)
+	for _, name := range names {
+		if strings.HasSuffix(name, ".log.gz") {
+			compressed[strings.TrimSuffix(name, ".gz")] = true
+		}
+	}
+	var segments []string
+	for _, name := range names {
+		if compressed[name] {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, w.name+"-")
+		if stamp == name {
+			continue
+		}
+		stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, ".gz"), ".log")
+		if _, err := time.Parse(logStamp, stamp); err != nil {
+			continue
+		}
+		segments = append(segments, filepath.Join(w.dir, name))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(segments)))
+	return segments, nil
+}
+
+// prune removes the oldest segments once they hold more than MaxTotal bytes
+func (w *logWriter) prune(segments []string) {
+	var total int64
+	for _, s := range segments {
+		info, err := os.Stat(s)
+		if err != nil {
+			continue
+		}
+		total += info.Size()
+		if total > w.rotation.MaxTotal {
+			os.Remove(s) // nolint: errcheck
+		}
+	}
+}
+
+// compressLog replaces the file at path with path.gz and returns the new path
+func compressLog(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close() // nolint: errcheck
+	err = atomicfile.Write(path+".gz", 0600, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		zw.Name = filepath.Base(path)
+		if _, err := io.Copy(zw, src); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+	if err != nil {
+		return "", err
+	}
+	src.Close() // nolint: errcheck
+	return path + ".gz", os.Remove(path)
+}
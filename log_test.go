@@ -0,0 +1,209 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func standupLogs(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_STATE_HOME", tmp) // nolint: errcheck
+	return filepath.Join(tmp, "OpenPeeDeeP", "XDG", "logs"), func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+// readLogs returns the contents of the rotated segments in dir, the oldest first, followed by the current file
+func readLogs(t *testing.T, dir, name string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, name+"-*.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	var logs []string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(zr)
+		f.Close() // nolint: errcheck
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, string(content))
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, name+".log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(logs, string(content))
+}
+
+func TestXDG_LogWriter(t *testing.T) {
+	assert := assert.New(t)
+	dir, teardown := standupLogs(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	assert.Equal(dir, x.LogDir())
+
+	w, err := x.LogWriter("app")
+	if !assert.NoError(err) {
+		return
+	}
+	fmt.Fprintln(w, "one") // nolint: errcheck
+	fmt.Fprintln(w, "two") // nolint: errcheck
+	assert.NoError(w.Close())
+	_, err = w.Write([]byte("three\n"))
+	assert.Equal(os.ErrClosed, err)
+
+	w, err = x.LogWriter("app")
+	if !assert.NoError(err) {
+		return
+	}
+	fmt.Fprintln(w, "three") // nolint: errcheck
+	assert.NoError(w.Close())
+	assert.Equal([]string{"one\ntwo\nthree\n"}, readLogs(t, dir, "app"))
+
+	_, err = x.LogWriter("../app")
+	assert.IsType(&UnsafeNameError{}, err)
+}
+
+func TestXDG_LogWriterRotateSize(t *testing.T) {
+	assert := assert.New(t)
+	dir, teardown := standupLogs(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.LogRotation.MaxSize = 12
+
+	w, err := x.LogWriter("app")
+	if !assert.NoError(err) {
+		return
+	}
+	defer w.Close() // nolint: errcheck
+	for _, line := range []string{"aaaa\n", "bbbb\n", "cccc\n", "dddd\n", "a line longer than MaxSize\n", "eeee\n"} {
+		_, err = w.Write([]byte(line))
+		assert.NoError(err)
+	}
+	assert.Equal([]string{"aaaa\nbbbb\n", "cccc\ndddd\n", "a line longer than MaxSize\n", "eeee\n"}, readLogs(t, dir, "app"))
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	assert.Empty(leftovers)
+}
+
+func TestXDG_LogWriterRotateAge(t *testing.T) {
+	assert := assert.New(t)
+	dir, teardown := standupLogs(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.LogRotation.MaxAge = time.Hour
+
+	w, err := x.LogWriter("app")
+	if !assert.NoError(err) {
+		return
+	}
+	defer w.Close()              // nolint: errcheck
+	fmt.Fprintln(w, "yesterday") // nolint: errcheck
+	fmt.Fprintln(w, "recently")  // nolint: errcheck
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(os.Chtimes(filepath.Join(dir, "app.log.lock"), old, old))
+	fmt.Fprintln(w, "today") // nolint: errcheck
+	fmt.Fprintln(w, "later") // nolint: errcheck
+	assert.Equal([]string{"yesterday\nrecently\n", "today\nlater\n"}, readLogs(t, dir, "app"))
+}
+
+func TestXDG_LogWriterPrune(t *testing.T) {
+	assert := assert.New(t)
+	dir, teardown := standupLogs(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.LogRotation.MaxSize = 1
+	w, err := x.LogWriter("app")
+	if !assert.NoError(err) {
+		return
+	}
+	defer w.Close()            // nolint: errcheck
+	fmt.Fprintln(w, "line", 0) // nolint: errcheck
+	fmt.Fprintln(w, "line", 1) // nolint: errcheck
+	segments, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if !assert.Len(segments, 1) {
+		return
+	}
+	info, err := os.Stat(segments[0])
+	if !assert.NoError(err) {
+		return
+	}
+
+	// Room for two segments
+	w.(*logWriter).rotation.MaxTotal = 2*info.Size() + 1
+	for i := 2; i < 5; i++ {
+		fmt.Fprintln(w, "line", i) // nolint: errcheck
+	}
+	assert.Equal([]string{"line 2\n", "line 3\n", "line 4\n"}, readLogs(t, dir, "app"))
+}
+
+func TestXDG_LogWriterConcurrent(t *testing.T) {
+	// fcntl locks are held by the process, so they do not keep writers of the same process apart.
+	// Windows does not rotate a file other writers have open.
+	if !lockfile.Supported || runtime.GOOS == "aix" || runtime.GOOS == "solaris" || runtime.GOOS == "windows" {
+		t.Skip("writers can not share a rotated file")
+	}
+	assert := assert.New(t)
+	dir, teardown := standupLogs(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.LogRotation.MaxSize = 100
+
+	// Every writer has its own files like a separate process would
+	const writers, lines = 4, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		w, err := x.LogWriter("app")
+		if !assert.NoError(err) {
+			return
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer w.Close() // nolint: errcheck
+			for j := 0; j < lines; j++ {
+				fmt.Fprintf(w, "writer %d line %d\n", i, j) // nolint: errcheck
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, log := range readLogs(t, dir, "app") {
+		assert.True(len(log) <= 100, log)
+		for _, line := range strings.SplitAfter(log, "\n") {
+			if line != "" {
+				assert.True(strings.HasSuffix(line, "\n"), line)
+				seen[line] = true
+			}
+		}
+	}
+	assert.Len(seen, writers*lines)
+}
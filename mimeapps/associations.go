@@ -0,0 +1,185 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mimeapps
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg"
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+)
+
+// Files returns the mimeapps.list files to read, the most important first.
+// Desktop specific files for the desktops in XDG_CURRENT_DESKTOP come before the common file of each directory.
+func Files() []string {
+	var desktops []string
+	for _, desktop := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if desktop != "" {
+			desktops = append(desktops, strings.ToLower(desktop))
+		}
+	}
+	var dirs []string
+	dirs = append(dirs, xdg.SearchDirs(xdg.Config)...)
+	for _, dir := range xdg.SearchDirs(xdg.Data) {
+		dirs = append(dirs, filepath.Join(dir, desktopentry.ApplicationsDir))
+	}
+	var files []string
+	for _, dir := range dirs {
+		for _, desktop := range desktops {
+			files = append(files, filepath.Join(dir, desktop+"-"+FileName))
+		}
+		files = append(files, filepath.Join(dir, FileName))
+	}
+	return files
+}
+
+// UserFile returns the mimeapps.list file the user's own choices are written to
+func UserFile() string {
+	return filepath.Join(xdg.ConfigHome(), FileName)
+}
+
+// Associations answers which applications open a MIME type
+type Associations struct {
+	lists   []*List
+	apps    map[string]string
+	ids     []string
+	entries map[string]*desktopentry.File
+}
+
+// Load reads every existing file returned by Files and indexes the installed desktop files
+func Load() (*Associations, error) {
+	a := &Associations{entries: make(map[string]*desktopentry.File)}
+	for _, path := range Files() {
+		l, err := ParseFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		a.lists = append(a.lists, l)
+	}
+	apps, err := desktopentry.Scan(xdg.SearchDirs(xdg.Data))
+	if err != nil {
+		return nil, err
+	}
+	a.apps = apps
+	for id := range apps {
+		a.ids = append(a.ids, id)
+	}
+	sort.Strings(a.ids)
+	return a, nil
+}
+
+// Installed reports whether a desktop file with the given ID is installed
+func (a *Associations) Installed(id string) bool {
+	_, ok := a.apps[id]
+	return ok
+}
+
+// Default returns the desktop file ID of the default application for mimeType.
+// When no default is set, the most preferred associated application is used.
+// Returns an empty string when no installed application handles mimeType.
+func (a *Associations) Default(mimeType string) string {
+	for _, l := range a.lists {
+		for _, id := range l.Get(DefaultApplications, mimeType) {
+			if a.Installed(id) {
+				return id
+			}
+		}
+	}
+	if apps := a.Apps(mimeType); len(apps) > 0 {
+		return apps[0]
+	}
+	return ""
+}
+
+// Apps returns the desktop file IDs of the installed applications associated with mimeType, the most preferred first.
+// Added and removed associations are applied in order of precedence, followed by the
+// applications listing mimeType in their MimeType key.
+func (a *Associations) Apps(mimeType string) []string {
+	var apps []string
+	seen := make(map[string]bool)
+	removed := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] && !removed[id] && a.Installed(id) {
+			seen[id] = true
+			apps = append(apps, id)
+		}
+	}
+	for _, l := range a.lists {
+		for _, id := range l.Get(AddedAssociations, mimeType) {
+			add(id)
+		}
+		for _, id := range l.Get(RemovedAssociations, mimeType) {
+			removed[id] = true
+		}
+	}
+	for _, dir := range xdg.SearchDirs(xdg.Data) {
+		appDir := filepath.Join(dir, desktopentry.ApplicationsDir) + string(filepath.Separator)
+		for _, id := range a.ids {
+			if !strings.HasPrefix(a.apps[id], appDir) {
+				continue
+			}
+			if a.handles(id, mimeType) {
+				add(id)
+			}
+		}
+	}
+	return apps
+}
+
+// handles reports whether the desktop file lists mimeType in its MimeType key
+func (a *Associations) handles(id, mimeType string) bool {
+	f, ok := a.entries[id]
+	if !ok {
+		f, _ = desktopentry.ParseFile(a.apps[id])
+		a.entries[id] = f
+	}
+	if f == nil {
+		return false
+	}
+	main := f.Group(desktopentry.MainGroup)
+	if main == nil || main.Bool("Hidden") {
+		return false
+	}
+	for _, handled := range main.Strings("MimeType") {
+		if handled == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// SetDefault makes the application with the given desktop file ID the user's default for mimeType.
+// The choice is written to UserFile, which is created when it does not exist.
+func SetDefault(mimeType, id string) error {
+	path := UserFile()
+	l, err := ParseFile(path)
+	if os.IsNotExist(err) {
+		l, err = new(List), nil
+	}
+	if err != nil {
+		return err
+	}
+	ids := []string{id}
+	for _, other := range l.Get(DefaultApplications, mimeType) {
+		if other != id {
+			ids = append(ids, other)
+		}
+	}
+	l.Set(DefaultApplications, mimeType, ids)
+	var added []string
+	for _, other := range l.Get(AddedAssociations, mimeType) {
+		if other != id {
+			added = append(added, other)
+		}
+	}
+	l.Set(AddedAssociations, mimeType, append([]string{id}, added...))
+	return l.Save(path)
+}
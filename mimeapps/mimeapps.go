@@ -0,0 +1,227 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package mimeapps reads and writes mimeapps.list files to find the applications associated with a MIME type.
+//
+// See https://specifications.freedesktop.org/mime-apps-spec/latest/ for the format and lookup rules.
+package mimeapps
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+)
+
+// FileName is the name of the file holding the associations of every desktop
+const FileName = "mimeapps.list"
+
+// The groups of a mimeapps.list file
+const (
+	DefaultApplications = "Default Applications"
+	AddedAssociations   = "Added Associations"
+	RemovedAssociations = "Removed Associations"
+)
+
+// SyntaxError is returned when a mimeapps.list file can not be parsed
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("mimeapps: line %d: %s", e.Line, e.Msg)
+}
+
+// List is a parsed mimeapps.list file.
+// Groups and keys are kept in the order they are written, so a List is written back unchanged.
+type List struct {
+	Path   string
+	groups []*group
+}
+
+type group struct {
+	name    string
+	entries []entry
+}
+
+// entry is either a MIME type and its desktop file IDs or a comment
+type entry struct {
+	mimeType string
+	ids      []string
+	comment  string
+}
+
+// Parse reads a mimeapps.list file from r
+func Parse(r io.Reader) (*List, error) {
+	l := new(List)
+	var g *group
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || text[0] == '#':
+			if g == nil {
+				g = &group{}
+				l.groups = append(l.groups, g)
+			}
+			g.entries = append(g.entries, entry{comment: text})
+		case text[0] == '[':
+			if text[len(text)-1] != ']' {
+				return nil, &SyntaxError{n, "unterminated group header"}
+			}
+			g = l.group(text[1:len(text)-1], true)
+		default:
+			eq := strings.IndexByte(text, '=')
+			if eq < 0 {
+				return nil, &SyntaxError{n, fmt.Sprintf("missing '=' in %q", text)}
+			}
+			if g == nil || g.name == "" {
+				return nil, &SyntaxError{n, "key outside of a group"}
+			}
+			g.entries = append(g.entries, entry{
+				mimeType: strings.TrimSpace(text[:eq]),
+				ids:      splitIDs(strings.TrimSpace(text[eq+1:])),
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ParseFile reads the mimeapps.list file at path
+func ParseFile(path string) (*List, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint: errcheck
+	l, err := Parse(file)
+	if err != nil {
+		return nil, err
+	}
+	l.Path = path
+	return l, nil
+}
+
+func splitIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ";") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (l *List) group(name string, add bool) *group {
+	for _, g := range l.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	if !add {
+		return nil
+	}
+	g := &group{name: name}
+	l.groups = append(l.groups, g)
+	return g
+}
+
+// Get returns the desktop file IDs listed for mimeType in the named group
+func (l *List) Get(groupName, mimeType string) []string {
+	g := l.group(groupName, false)
+	if g == nil {
+		return nil
+	}
+	for _, e := range g.entries {
+		if e.mimeType == mimeType {
+			return e.ids
+		}
+	}
+	return nil
+}
+
+// Set replaces the desktop file IDs listed for mimeType in the named group.
+// The entry is removed when ids is empty.
+func (l *List) Set(groupName, mimeType string, ids []string) {
+	g := l.group(groupName, len(ids) > 0)
+	if g == nil {
+		return
+	}
+	for i, e := range g.entries {
+		if e.mimeType != mimeType {
+			continue
+		}
+		if len(ids) == 0 {
+			g.entries = append(g.entries[:i], g.entries[i+1:]...)
+			return
+		}
+		g.entries[i].ids = ids
+		return
+	}
+	if len(ids) > 0 {
+		g.entries = append(g.entries, entry{mimeType: mimeType, ids: ids})
+	}
+}
+
+// MimeTypes returns the MIME types listed in the named group
+func (l *List) MimeTypes(groupName string) []string {
+	g := l.group(groupName, false)
+	if g == nil {
+		return nil
+	}
+	var mimeTypes []string
+	for _, e := range g.entries {
+		if e.mimeType != "" {
+			mimeTypes = append(mimeTypes, e.mimeType)
+		}
+	}
+	return mimeTypes
+}
+
+// WriteTo writes the list to w
+func (l *List) WriteTo(w io.Writer) (int64, error) {
+	buf := new(bytes.Buffer)
+	for i, g := range l.groups {
+		if g.name != "" {
+			if i > 0 && l.groups[i-1].name != "" && !bytes.HasSuffix(buf.Bytes(), []byte("\n\n")) {
+				buf.WriteString("\n")
+			}
+			buf.WriteString("[" + g.name + "]\n")
+		}
+		for _, e := range g.entries {
+			if e.mimeType == "" {
+				buf.WriteString(e.comment + "\n")
+				continue
+			}
+			buf.WriteString(e.mimeType + "=" + strings.Join(e.ids, ";") + ";\n")
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// Save writes the list to path, replacing any existing file atomically
+func (l *List) Save(path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	err := atomicfile.Write(path, 0644, func(w io.Writer) error {
+		_, err := l.WriteTo(w)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	l.Path = path
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mimeapps
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `# Chosen by the user
+[Default Applications]
+text/markdown=typora.desktop;gedit.desktop;
+
+[Added Associations]
+text/markdown=typora.desktop;
+text/plain=gedit.desktop;vim.desktop;
+
+[Removed Associations]
+text/plain=nano.desktop;
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	l, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(err) {
+		return
+	}
+	var buf bytes.Buffer
+	_, err = l.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Equal(sample, buf.String())
+	assert.Equal([]string{"typora.desktop", "gedit.desktop"}, l.Get(DefaultApplications, "text/markdown"))
+	assert.Equal([]string{"text/markdown", "text/plain"}, l.MimeTypes(AddedAssociations))
+	assert.Nil(l.Get(DefaultApplications, "text/plain"))
+	assert.Nil(l.Get("Missing", "text/plain"))
+}
+
+func TestListSet(t *testing.T) {
+	assert := assert.New(t)
+	l := new(List)
+	l.Set(DefaultApplications, "text/plain", []string{"gedit.desktop"})
+	l.Set(RemovedAssociations, "text/plain", []string{"nano.desktop"})
+	l.Set(DefaultApplications, "text/plain", []string{"vim.desktop", "gedit.desktop"})
+	l.Set(RemovedAssociations, "text/plain", nil)
+	var buf bytes.Buffer
+	_, err := l.WriteTo(&buf)
+	assert.NoError(err)
+	assert.Equal("[Default Applications]\ntext/plain=vim.desktop;gedit.desktop;\n\n[Removed Associations]\n", buf.String())
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"text/plain=a.desktop\n", "[Default Applications\n", "[Default Applications]\ntext/plain\n"} {
+		t.Run(input, func(t *testing.T) {
+			assert := assert.New(t)
+			_, err := Parse(strings.NewReader(input))
+			assert.IsType(&SyntaxError{}, err)
+		})
+	}
+}
+
+func standupAssociations(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "mimeapps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config")) // nolint: errcheck
+	os.Setenv("XDG_CONFIG_DIRS", filepath.Join(tmp, "etc"))    // nolint: errcheck
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))     // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmp, "share"))    // nolint: errcheck
+	os.Setenv("XDG_CURRENT_DESKTOP", "X-Cinnamon:GNOME")       // nolint: errcheck
+	for id, mimeTypes := range map[string][]string{
+		"gedit.desktop":       {"text/plain", "text/markdown"},
+		"vim.desktop":         {"text/plain"},
+		"nano.desktop":        {"text/plain"},
+		"typora.desktop":      {"text/markdown"},
+		"ghostwriter.desktop": {"text/markdown"},
+	} {
+		f := desktopentry.New(desktopentry.Application, id)
+		f.Main().Set("Exec", id)
+		f.Main().SetStrings("MimeType", mimeTypes)
+		if err = f.Save(filepath.Join(tmp, "share", desktopentry.ApplicationsDir, id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tmp, func() {
+		os.Unsetenv("XDG_CURRENT_DESKTOP") // nolint: errcheck
+		os.RemoveAll(tmp)                  // nolint: errcheck
+	}
+}
+
+func writeList(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAssociations(t)
+	defer teardown()
+	assert.Equal([]string{
+		filepath.Join(tmp, "config", "x-cinnamon-mimeapps.list"),
+		filepath.Join(tmp, "config", "gnome-mimeapps.list"),
+		filepath.Join(tmp, "config", "mimeapps.list"),
+		filepath.Join(tmp, "etc", "x-cinnamon-mimeapps.list"),
+		filepath.Join(tmp, "etc", "gnome-mimeapps.list"),
+		filepath.Join(tmp, "etc", "mimeapps.list"),
+		filepath.Join(tmp, "data", "applications", "x-cinnamon-mimeapps.list"),
+		filepath.Join(tmp, "data", "applications", "gnome-mimeapps.list"),
+		filepath.Join(tmp, "data", "applications", "mimeapps.list"),
+		filepath.Join(tmp, "share", "applications", "x-cinnamon-mimeapps.list"),
+		filepath.Join(tmp, "share", "applications", "gnome-mimeapps.list"),
+		filepath.Join(tmp, "share", "applications", "mimeapps.list"),
+	}, Files())
+}
+
+func TestAssociations(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAssociations(t)
+	defer teardown()
+	writeList(t, filepath.Join(tmp, "config", "mimeapps.list"), "[Default Applications]\ntext/markdown=missing.desktop;typora.desktop;\n[Removed Associations]\ntext/plain=nano.desktop;\n")
+	writeList(t, filepath.Join(tmp, "etc", "gnome-mimeapps.list"), "[Default Applications]\ntext/markdown=ghostwriter.desktop;\ntext/plain=vim.desktop;\n")
+	writeList(t, filepath.Join(tmp, "share", "applications", "mimeapps.list"), "[Added Associations]\ntext/plain=nano.desktop;gedit.desktop;\n")
+
+	a, err := Load()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.True(a.Installed("gedit.desktop"))
+	assert.False(a.Installed("missing.desktop"))
+	assert.Equal("typora.desktop", a.Default("text/markdown"))
+	assert.Equal("vim.desktop", a.Default("text/plain"))
+	assert.Equal([]string{"gedit.desktop", "vim.desktop"}, a.Apps("text/plain"))
+	assert.Equal([]string{"gedit.desktop", "ghostwriter.desktop", "typora.desktop"}, a.Apps("text/markdown"))
+	assert.Equal("", a.Default("image/png"))
+}
+
+func TestDefaultFallback(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupAssociations(t)
+	defer teardown()
+
+	a, err := Load()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("gedit.desktop", a.Default("text/plain"))
+}
+
+func TestSetDefault(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupAssociations(t)
+	defer teardown()
+	writeList(t, UserFile(), "# Keep me\n[Default Applications]\ntext/plain=vim.desktop;\ntext/markdown=gedit.desktop;\n")
+
+	assert.NoError(SetDefault("text/markdown", "typora.desktop"))
+	assert.NoError(SetDefault("text/plain", "vim.desktop"))
+	content, err := ioutil.ReadFile(filepath.Join(tmp, "config", "mimeapps.list"))
+	assert.NoError(err)
+	assert.Equal("# Keep me\n[Default Applications]\ntext/plain=vim.desktop;\ntext/markdown=typora.desktop;gedit.desktop;\n\n[Added Associations]\ntext/markdown=typora.desktop;\ntext/plain=vim.desktop;\n", string(content))
+
+	a, err := Load()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal("typora.desktop", a.Default("text/markdown"))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NamingPolicy returns the path appended to the base directories of kind for an application
+type NamingPolicy func(kind Kind, vendor, application string) string
+
+// VendorAppNaming joins the vendor and application names verbatim, such as Vendor/App
+func VendorAppNaming(kind Kind, vendor, application string) string {
+	return filepath.Join(vendor, application)
+}
+
+// LowercaseNaming uses the lowercase application name without the vendor, such as app
+func LowercaseNaming(kind Kind, vendor, application string) string {
+	return strings.ToLower(application)
+}
+
+// ReverseDNSNaming uses a reverse domain name bundle identifier, such as com.vendor.app
+func ReverseDNSNaming(kind Kind, vendor, application string) string {
+	parts := []string{"com"}
+	for _, name := range []string{vendor, application} {
+		if name = bundleName(name); name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// PlatformNaming uses the convention of the running operating system.
+// That is ReverseDNSNaming on macOS, VendorAppNaming on Windows and LowercaseNaming everywhere else.
+func PlatformNaming(kind Kind, vendor, application string) string {
+	return platformNaming(runtime.GOOS)(kind, vendor, application)
+}
+
+func platformNaming(goos string) NamingPolicy {
+	switch goos {
+	case "darwin":
+		return ReverseDNSNaming
+	case "windows":
+		return VendorAppNaming
+	}
+	return LowercaseNaming
+}
+
+// bundleName lowercases name and replaces the characters not allowed in a bundle identifier with hyphens
+func bundleName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, name)
+}
+
+func (x *XDG) name(kind Kind) string {
+	naming := x.Naming
+	if naming == nil {
+		naming = VendorAppNaming
+	}
+	return naming(kind, x.Vendor, x.Application)
+}
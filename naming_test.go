@@ -0,0 +1,69 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type namingTestCase struct {
+	name     string
+	policy   NamingPolicy
+	vendor   string
+	app      string
+	expected string
+}
+
+var namingTestCases = []namingTestCase{
+	{"VendorApp", VendorAppNaming, "OpenPeeDeeP", "XDG", filepath.Clean("OpenPeeDeeP/XDG")},
+	{"VendorApp No Vendor", VendorAppNaming, "", "XDG", "XDG"},
+	{"Lowercase", LowercaseNaming, "OpenPeeDeeP", "XDG", "xdg"},
+	{"ReverseDNS", ReverseDNSNaming, "OpenPeeDeeP", "XDG", "com.openpeedeep.xdg"},
+	{"ReverseDNS Spaces", ReverseDNSNaming, "Open PeeDeeP", "My App", "com.open-peedeep.my-app"},
+	{"ReverseDNS No Vendor", ReverseDNSNaming, "", "XDG", "com.xdg"},
+}
+
+func TestNamingPolicies(t *testing.T) {
+	for _, tc := range namingTestCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expected, tc.policy(Config, tc.vendor, tc.app))
+		})
+	}
+}
+
+func TestPlatformNaming(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("com.openpeedeep.xdg", platformNaming("darwin")(Data, "OpenPeeDeeP", "XDG"))
+	assert.Equal(filepath.Clean("OpenPeeDeeP/XDG"), platformNaming("windows")(Data, "OpenPeeDeeP", "XDG"))
+	assert.Equal("xdg", platformNaming("linux")(Data, "OpenPeeDeeP", "XDG"))
+	assert.Equal("xdg", platformNaming("freebsd")(Data, "OpenPeeDeeP", "XDG"))
+}
+
+func TestXDG_Naming(t *testing.T) {
+	assert := assert.New(t)
+	dataDirs := strings.Join([]string{filepath.Clean("/a"), filepath.Clean("/b")}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Clean("/data"))   // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                  // nolint: errcheck
+	os.Setenv("XDG_CACHE_HOME", filepath.Clean("/cache")) // nolint: errcheck
+
+	x := New("OpenPeeDeeP", "XDG")
+	assert.Equal(filepath.Clean("/data/OpenPeeDeeP/XDG"), x.DataHome())
+
+	x.Naming = func(kind Kind, vendor, application string) string {
+		if kind == Cache {
+			return "cache-" + application
+		}
+		return LowercaseNaming(kind, vendor, application)
+	}
+	assert.Equal(filepath.Clean("/data/xdg"), x.DataHome())
+	assert.Equal([]string{filepath.Clean("/a/xdg"), filepath.Clean("/b/xdg")}, x.DataDirs())
+	assert.Equal(filepath.Clean("/cache/cache-XDG"), x.CacheHome())
+}
@@ -0,0 +1,22 @@
+// +build windows plan9
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import "os"
+
+// privatePerm is false where permission bits do not reflect who can access a file
+const privatePerm = false
+
+// ownedByUser always reports true, the per-user profile directories are already private
+func ownedByUser(info os.FileInfo) bool {
+	return true
+}
+
+// ownedByRoot always reports false, there is no superuser owning files
+func ownedByRoot(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,27 @@
+// +build !windows,!plan9
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"syscall"
+)
+
+// privatePerm is true where permission bits keep other users out
+const privatePerm = true
+
+// ownedByUser reports whether the file is owned by the user running the process
+func ownedByUser(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return !ok || int(st.Uid) == os.Getuid()
+}
+
+// ownedByRoot reports whether the file is owned by the superuser, like files installed by a package manager
+func ownedByRoot(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	return ok && st.Uid == 0
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Paths holds every directory an application resolved at one moment, so they can be reported or
+// handed to a child process as one value. The slices it returns are copies and decoding only fills
+// a zero Paths, ErrPathsSet is returned otherwise, so a *Paths can be shared freely.
+type Paths struct {
+	kinds [Runtime + 1]kindPaths
+}
+
+type kindPaths struct {
+	Home string   `json:"home,omitempty"`
+	Dirs []string `json:"dirs,omitempty"`
+}
+
+// jsonPaths gives the JSON form of Paths its fields in the order of the kinds
+type jsonPaths struct {
+	Data    kindPaths `json:"data"`
+	Config  kindPaths `json:"config"`
+	Cache   kindPaths `json:"cache"`
+	State   kindPaths `json:"state"`
+	Runtime kindPaths `json:"runtime"`
+}
+
+// ErrPathsSet is returned when decoding into Paths that hold directories already
+var ErrPathsSet = errors.New("xdg: can not decode into Paths holding directories")
+
+// Resolve returns the home and system wide directories of every kind for this specific application.
+// The environment is read once for each kind. The runtime directory is the configured one: the
+// private fallback chosen by RuntimeDir is not created, so it is left out when XDG_RUNTIME_DIR is not set.
+func (x *XDG) Resolve() *Paths {
+	p := new(Paths)
+	for kind := range p.kinds {
+		p.kinds[kind].Home = x.home(Kind(kind))
+		if dirs := cleanDirs(x.dirs(Kind(kind))); len(dirs) > 0 {
+			p.kinds[kind].Dirs = dirs
+		}
+	}
+	return p
+}
+
+func (p *Paths) kind(kind Kind) kindPaths {
+	if kind < 0 || int(kind) >= len(p.kinds) {
+		return kindPaths{}
+	}
+	return p.kinds[kind]
+}
+
+// Home returns the home directory of kind, or an empty string when there is none
+func (p *Paths) Home(kind Kind) string {
+	return p.kind(kind).Home
+}
+
+// Dirs returns the system wide directories of kind
+func (p *Paths) Dirs(kind Kind) []string {
+	return append([]string(nil), p.kind(kind).Dirs...)
+}
+
+// SearchDirs returns the home directory of kind followed by its system wide directories, without duplicates
+func (p *Paths) SearchDirs(kind Kind) []string {
+	k := p.kind(kind)
+	return cleanDirs(append([]string{k.Home}, k.Dirs...))
+}
+
+// MarshalJSON encodes the paths as an object with a member for each kind holding its home and dirs
+func (p *Paths) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonPaths{
+		Data:    p.kinds[Data],
+		Config:  p.kinds[Config],
+		Cache:   p.kinds[Cache],
+		State:   p.kinds[State],
+		Runtime: p.kinds[Runtime],
+	})
+}
+
+// ParsePathsJSON decodes paths encoded by MarshalJSON
+func ParsePathsJSON(data []byte) (*Paths, error) {
+	var j jsonPaths
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return &Paths{kinds: [...]kindPaths{j.Data, j.Config, j.Cache, j.State, j.Runtime}}, nil
+}
+
+// UnmarshalJSON decodes paths encoded by MarshalJSON into a zero Paths, like ParsePathsJSON
+func (p *Paths) UnmarshalJSON(data []byte) error {
+	if !p.zero() {
+		return ErrPathsSet
+	}
+	decoded, err := ParsePathsJSON(data)
+	if err != nil {
+		return err
+	}
+	p.kinds = decoded.kinds
+	return nil
+}
+
+// MarshalText encodes the paths as lines such as data_home=/home/user/.local/share/Vendor/App.
+// The system wide directories are joined with the platform's list separator, like in XDG_DATA_DIRS.
+// Kinds without directories are left out.
+func (p *Paths) MarshalText() ([]byte, error) {
+	var buf bytes.Buffer
+	for kind, k := range p.kinds {
+		if k.Home != "" {
+			fmt.Fprintf(&buf, "%s_home=%s\n", Kind(kind), k.Home)
+		}
+		if len(k.Dirs) > 0 {
+			fmt.Fprintf(&buf, "%s_dirs=%s\n", Kind(kind), strings.Join(k.Dirs, string(filepath.ListSeparator)))
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// ParsePaths decodes paths encoded by MarshalText
+func ParsePaths(text []byte) (*Paths, error) {
+	var kinds [Runtime + 1]kindPaths
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return nil, fmt.Errorf("xdg: line %d: missing '=' in %q", n, line)
+		}
+		key, value := line[:eq], line[eq+1:]
+		kind, ok := kindByName(strings.TrimSuffix(strings.TrimSuffix(key, "_home"), "_dirs"))
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("xdg: line %d: unknown key %q", n, key)
+		case strings.HasSuffix(key, "_home"):
+			kinds[kind].Home = value
+		case strings.HasSuffix(key, "_dirs"):
+			kinds[kind].Dirs = filepath.SplitList(value)
+		default:
+			return nil, fmt.Errorf("xdg: line %d: unknown key %q", n, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &Paths{kinds: kinds}, nil
+}
+
+// UnmarshalText decodes paths encoded by MarshalText into a zero Paths, like ParsePaths
+func (p *Paths) UnmarshalText(text []byte) error {
+	if !p.zero() {
+		return ErrPathsSet
+	}
+	decoded, err := ParsePaths(text)
+	if err != nil {
+		return err
+	}
+	p.kinds = decoded.kinds
+	return nil
+}
+
+// zero reports whether p holds no directories
+func (p *Paths) zero() bool {
+	for _, k := range p.kinds {
+		if k.Home != "" || len(k.Dirs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the text encoding of the paths, for logs and crash reports
+func (p *Paths) String() string {
+	text, _ := p.MarshalText()
+	return string(text)
+}
+
+func kindByName(name string) (Kind, bool) {
+	for kind, kindName := range kindNames {
+		if kindName == name {
+			return Kind(kind), true
+		}
+	}
+	return 0, false
+}
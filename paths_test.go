@@ -0,0 +1,119 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupPaths() func() {
+	dataDirs := strings.Join([]string{filepath.Clean("/usr/local/share"), filepath.Clean("/usr/share")}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Clean("/home/user/.local/share"))  // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                                   // nolint: errcheck
+	os.Setenv("XDG_CONFIG_HOME", filepath.Clean("/home/user/.config"))     // nolint: errcheck
+	os.Setenv("XDG_CONFIG_DIRS", filepath.Clean("/etc/xdg"))               // nolint: errcheck
+	os.Setenv("XDG_CACHE_HOME", filepath.Clean("/home/user/.cache"))       // nolint: errcheck
+	os.Setenv("XDG_STATE_HOME", filepath.Clean("/home/user/.local/state")) // nolint: errcheck
+	os.Setenv("XDG_RUNTIME_DIR", filepath.Clean("/run/user/1000"))         // nolint: errcheck
+	return func() {
+		os.Unsetenv("XDG_RUNTIME_DIR") // nolint: errcheck
+	}
+}
+
+func TestXDG_Resolve(t *testing.T) {
+	assert := assert.New(t)
+	defer standupPaths()()
+	x := New("OpenPeeDeeP", "XDG")
+
+	p := x.Resolve()
+	for _, kind := range []Kind{Data, Config, Cache, State, Runtime} {
+		assert.Equal(x.home(kind), p.Home(kind), kind.String())
+		assert.Equal(x.SearchDirs(kind), p.SearchDirs(kind), kind.String())
+	}
+	assert.Equal(x.DataDirs(), p.Dirs(Data))
+	assert.Equal(x.ConfigDirs(), p.Dirs(Config))
+	assert.Empty(p.Dirs(Cache))
+	assert.Equal("", p.Home(Kind(42)))
+
+	// Changes to the environment or to returned slices do not show through
+	os.Setenv("XDG_CACHE_HOME", filepath.Clean("/tmp/cache")) // nolint: errcheck
+	dirs := p.Dirs(Data)
+	dirs[0] = "changed"
+	assert.Equal(filepath.Clean("/home/user/.cache/OpenPeeDeeP/XDG"), p.Home(Cache))
+	assert.Equal(x.DataDirs(), p.Dirs(Data))
+}
+
+func TestPaths_JSON(t *testing.T) {
+	assert := assert.New(t)
+	defer standupPaths()()
+	p := New("OpenPeeDeeP", "XDG").Resolve()
+
+	data, err := json.Marshal(p)
+	if !assert.NoError(err) {
+		return
+	}
+	var decoded map[string]map[string]interface{}
+	assert.NoError(json.Unmarshal(data, &decoded))
+	assert.Equal(filepath.Clean("/home/user/.config/OpenPeeDeeP/XDG"), decoded["config"]["home"])
+	assert.Len(decoded["data"]["dirs"], 2)
+	assert.NotContains(decoded["cache"], "dirs")
+	assert.True(strings.HasPrefix(string(data), `{"data":`))
+
+	copied, err := ParsePathsJSON(data)
+	assert.NoError(err)
+	assert.Equal(p, copied)
+	_, err = ParsePathsJSON([]byte(`{"data":[]}`))
+	assert.Error(err)
+
+	// Decoding fills a zero Paths, also inside another value, but never changes resolved ones
+	var bundle struct {
+		Paths *Paths `json:"paths"`
+	}
+	assert.NoError(json.Unmarshal([]byte(`{"paths":`+string(data)+`}`), &bundle))
+	assert.Equal(p, bundle.Paths)
+	assert.Equal(ErrPathsSet, json.Unmarshal(data, p))
+}
+
+func TestPaths_Text(t *testing.T) {
+	assert := assert.New(t)
+	defer standupPaths()()
+	x := New("OpenPeeDeeP", "XDG")
+	p := x.Resolve()
+
+	text, err := p.MarshalText()
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(strings.Join([]string{
+		"data_home=" + x.DataHome(),
+		"data_dirs=" + strings.Join(x.DataDirs(), string(filepath.ListSeparator)),
+		"config_home=" + x.ConfigHome(),
+		"config_dirs=" + strings.Join(x.ConfigDirs(), string(filepath.ListSeparator)),
+		"cache_home=" + x.CacheHome(),
+		"state_home=" + x.StateHome(),
+		"runtime_home=" + x.home(Runtime),
+	}, "\n")+"\n", string(text))
+	assert.Equal(string(text), p.String())
+
+	copied, err := ParsePaths(text)
+	assert.NoError(err)
+	assert.Equal(p, copied)
+
+	for _, bad := range []string{"data_home", "music_home=/music", "data=/data"} {
+		_, err = ParsePaths([]byte(bad))
+		assert.Error(err, bad)
+	}
+
+	decoded := new(Paths)
+	assert.NoError(decoded.UnmarshalText(text))
+	assert.Equal(p, decoded)
+	assert.Equal(ErrPathsSet, decoded.UnmarshalText(text))
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+)
+
+// StalePIDError is returned when a PID file names a process that is no longer running
+type StalePIDError struct {
+	Path string
+	PID  int
+}
+
+func (e *StalePIDError) Error() string {
+	return "xdg: process " + strconv.Itoa(e.PID) + " in " + e.Path + " is not running"
+}
+
+// PIDFile returns the path of the PID file called name, such as daemon for daemon.pid, in RuntimeDir
+func (x *XDG) PIDFile(name string) (string, error) {
+	dir, err := x.RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	if !plainName(name + ".pid") {
+		return "", &UnsafeNameError{Name: name, Dir: dir}
+	}
+	return filepath.Join(dir, name+".pid"), nil
+}
+
+// WritePIDFile atomically writes the PID of the running process to the PID file called name and returns its path.
+// An *AlreadyRunningError is returned when the file names another running instance of this executable.
+// A stale file is replaced. The file is checked and written while holding name.pid.lock, so when several
+// processes start at once only one of them writes its PID.
+func (x *XDG) WritePIDFile(name string) (string, error) {
+	path, err := x.PIDFile(name)
+	if err != nil {
+		return "", err
+	}
+	lock, err := lockfile.Lock(path + ".lock")
+	if err != nil {
+		return "", err
+	}
+	defer lock.Unlock() // nolint: errcheck
+	pid, err := readPIDFile(path)
+	switch err.(type) {
+	case nil:
+		if pid != os.Getpid() {
+			return "", &AlreadyRunningError{Path: path, PID: pid}
+		}
+	case *StalePIDError:
+	default:
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	if err = atomicfile.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadPIDFile returns the PID in the PID file called name.
+// A *StalePIDError is returned when that process is not running or, where /proc tells, runs another executable.
+func (x *XDG) ReadPIDFile(name string) (int, error) {
+	path, err := x.PIDFile(name)
+	if err != nil {
+		return 0, err
+	}
+	return readPIDFile(path)
+}
+
+// RemovePIDFile removes the PID file called name unless it names another running process.
+// A missing file is not an error. name.pid.lock is kept, as other processes may be waiting on it.
+func (x *XDG) RemovePIDFile(name string) error {
+	path, err := x.PIDFile(name)
+	if err != nil {
+		return err
+	}
+	lock, err := lockfile.Lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock() // nolint: errcheck
+	pid, err := readPIDFile(path)
+	switch err.(type) {
+	case nil:
+		if pid != os.Getpid() {
+			return &AlreadyRunningError{Path: path, PID: pid}
+		}
+	case *StalePIDError:
+	default:
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err = os.Remove(path); os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func readPIDFile(path string) (int, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || pid <= 0 {
+		return 0, &StalePIDError{Path: path}
+	}
+	if !processRunning(pid) {
+		return 0, &StalePIDError{Path: path, PID: pid}
+	}
+	return pid, nil
+}
+
+// processRunning reports whether the process is alive and, where /proc links to its executable, runs this executable.
+// A PID reused by an unrelated process after a crash is not mistaken for a running instance.
+func processRunning(pid int) bool {
+	if !processAlive(pid) {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	target, err := os.Readlink(filepath.Join(string(filepath.Separator), "proc", strconv.Itoa(pid), "exe"))
+	if err != nil {
+		return true
+	}
+	// The executable of a running process may have been replaced by an upgrade
+	target = strings.TrimSuffix(target, " (deleted)")
+	exe, err := executable()
+	if err != nil {
+		return true
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return target == exe
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXDG_WritePIDFile(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	path := filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG", "daemon.pid")
+
+	_, err := x.ReadPIDFile("daemon")
+	assert.True(os.IsNotExist(err))
+	written, err := x.WritePIDFile("daemon")
+	assert.NoError(err)
+	assert.Equal(path, written)
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(strconv.Itoa(os.Getpid())+"\n", string(content))
+	pid, err := x.ReadPIDFile("daemon")
+	assert.NoError(err)
+	assert.Equal(os.Getpid(), pid)
+
+	_, err = x.WritePIDFile("daemon")
+	assert.NoError(err)
+	assert.NoError(x.RemovePIDFile("daemon"))
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+	assert.NoError(x.RemovePIDFile("daemon"))
+
+	_, err = x.WritePIDFile("../daemon")
+	assert.IsType(&UnsafeNameError{}, err)
+}
+
+func TestXDG_PIDFileStale(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	dir := filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG")
+	path := filepath.Join(dir, "daemon.pid")
+	assert.NoError(os.MkdirAll(dir, 0700))
+	stale := 1 << 30
+	assert.NoError(ioutil.WriteFile(path, []byte(strconv.Itoa(stale)+"\n"), 0644))
+
+	_, err := x.ReadPIDFile("daemon")
+	assert.Equal(&StalePIDError{Path: path, PID: stale}, err)
+	_, err = x.WritePIDFile("daemon")
+	assert.NoError(err)
+	assert.NoError(ioutil.WriteFile(path, []byte(strconv.Itoa(stale)+"\n"), 0644))
+	assert.NoError(x.RemovePIDFile("daemon"))
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+}
+
+func TestXDG_PIDFileExecutable(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc is only checked on Linux")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	cmd := exec.Command(sleep, "60")
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait()         // nolint: errcheck
+	defer cmd.Process.Kill() // nolint: errcheck
+	x := New("OpenPeeDeeP", "XDG")
+	dir := filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG")
+	path := filepath.Join(dir, "daemon.pid")
+	assert.NoError(os.MkdirAll(dir, 0700))
+	assert.NoError(ioutil.WriteFile(path, []byte(strconv.Itoa(cmd.Process.Pid)+"\n"), 0644))
+
+	// A PID reused by another program
+	_, err = x.ReadPIDFile("daemon")
+	assert.Equal(&StalePIDError{Path: path, PID: cmd.Process.Pid}, err)
+
+	target, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "exe"))
+	if !assert.NoError(err) {
+		return
+	}
+	executable = func() (string, error) {
+		return target, nil
+	}
+	defer func() {
+		executable = os.Executable
+	}()
+	pid, err := x.ReadPIDFile("daemon")
+	assert.NoError(err)
+	assert.Equal(cmd.Process.Pid, pid)
+	_, err = x.WritePIDFile("daemon")
+	assert.Equal(&AlreadyRunningError{Path: path, PID: cmd.Process.Pid}, err)
+	assert.Equal(&AlreadyRunningError{Path: path, PID: cmd.Process.Pid}, x.RemovePIDFile("daemon"))
+}
+
+func TestXDG_WritePIDFileRace(t *testing.T) {
+	if !lockfile.Supported {
+		t.Skip("file locks are not supported")
+	}
+	assert := assert.New(t)
+	_, teardown := standupRuntime(t)
+	defer teardown()
+	const n = 4
+	var procs []*exec.Cmd
+	var results []*bufio.Reader
+	for i := 0; i < n; i++ {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPIDFileHelperProcess$")
+		cmd.Env = append(os.Environ(), "XDG_TEST_PIDFILE_HELPER=1")
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = cmd.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer cmd.Wait()    // nolint: errcheck
+		defer stdin.Close() // nolint: errcheck
+		procs = append(procs, cmd)
+		results = append(results, bufio.NewReader(stdout))
+	}
+
+	// Every helper stays alive until all of them reported, so none of the PIDs is stale
+	won := 0
+	for i, result := range results {
+		line, err := result.ReadString('\n')
+		if !assert.NoError(err) {
+			continue
+		}
+		switch strings.TrimSpace(line) {
+		case "written":
+			won++
+			pid, err := New("OpenPeeDeeP", "XDG").ReadPIDFile("daemon")
+			assert.NoError(err)
+			assert.Equal(procs[i].Process.Pid, pid)
+		case "running":
+		default:
+			t.Errorf("unexpected result %q", line)
+		}
+	}
+	assert.Equal(1, won)
+}
+
+// TestPIDFileHelperProcess writes the PID file for TestXDG_WritePIDFileRace and waits for stdin to be closed
+func TestPIDFileHelperProcess(t *testing.T) {
+	if os.Getenv("XDG_TEST_PIDFILE_HELPER") != "1" {
+		return
+	}
+	_, err := New("OpenPeeDeeP", "XDG").WritePIDFile("daemon")
+	switch err.(type) {
+	case nil:
+		fmt.Println("written")
+	case *AlreadyRunningError:
+		fmt.Println("running")
+	default:
+		fmt.Println(err)
+	}
+	io.Copy(ioutil.Discard, os.Stdin) // nolint: errcheck
+	os.Exit(0)
+}
+
+func TestSystemRuntimeDir(t *testing.T) {
+	programData, set := os.LookupEnv("PROGRAMDATA")
+	os.Setenv("PROGRAMDATA", `C:\ProgramData`) // nolint: errcheck
+	defer func() {
+		if set {
+			os.Setenv("PROGRAMDATA", programData) // nolint: errcheck
+		} else {
+			os.Unsetenv("PROGRAMDATA") // nolint: errcheck
+		}
+	}()
+	tests := []struct {
+		goos string
+		dir  string
+	}{
+		{"linux", filepath.Join(string(filepath.Separator), "run")},
+		{"android", filepath.Join(string(filepath.Separator), "run")},
+		{"freebsd", filepath.Join(string(filepath.Separator), "var", "run")},
+		{"darwin", filepath.Join(string(filepath.Separator), "var", "run")},
+		{"windows", `C:\ProgramData`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			assert.Equal(t, tt.dir, systemRuntimeDir(tt.goos))
+		})
+	}
+}
+
+func TestXDG_System(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.System = true
+	dir := filepath.Join(systemRuntimeDir(runtime.GOOS), "OpenPeeDeeP", "XDG")
+	assert.Equal(Explanation{Kind: Runtime, Path: dir, Source: SourceDefault}, x.Explain(Runtime))
+	assert.Equal(dir, x.home(Runtime))
+
+	x.EnvPrefix = "XDG_TEST"
+	os.Setenv("XDG_TEST_RUNTIME_DIR", "/srv/run") // nolint: errcheck
+	defer os.Unsetenv("XDG_TEST_RUNTIME_DIR")     // nolint: errcheck
+	assert.Equal(Explanation{Kind: Runtime, Path: "/srv/run", Source: SourceApp, Env: "XDG_TEST_RUNTIME_DIR"}, x.Explain(Runtime))
+}
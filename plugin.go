@@ -0,0 +1,88 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// DefaultPluginManifest is the manifest file of a plugin when PluginManifest is not set
+const DefaultPluginManifest = "plugin.json"
+
+// Plugin is a directory holding a plugin found by DiscoverPlugins
+type Plugin struct {
+	// Name is the name of the plugin directory
+	Name     string
+	Dir      string
+	Manifest string
+	// Origin is the directory the plugin was found in, such as /usr/share/vendor/app/plugins
+	Origin string
+	// User is true for plugins installed in DataHome, false for system wide plugins
+	User bool
+	// Shadowed lists the directories of plugins with the same name in less important
+	// directories, which are hidden by this one
+	Shadowed []string
+}
+
+// DiscoverPlugins returns the plugins in subdir of DataHome and DataDirs, sorted by name.
+// A plugin is a directory holding the file named by PluginManifest. A plugin shadows the
+// plugins with the same name in less important directories, so a user can override a
+// system wide plugin.
+func (x *XDG) DiscoverPlugins(subdir string) ([]Plugin, error) {
+	dirs := x.SearchDirs(Data)
+	if len(dirs) == 0 {
+		return nil, ErrUnsupported
+	}
+	if escapes(subdir) {
+		return nil, &UnsafeNameError{Name: subdir, Dir: dirs[0]}
+	}
+	manifest := x.PluginManifest
+	if manifest == "" {
+		manifest = DefaultPluginManifest
+	}
+	userDir := filepath.Clean(x.home(Data))
+	found := make(map[string]int)
+	var plugins []Plugin
+	for _, dir := range dirs {
+		origin := filepath.Join(dir, subdir)
+		entries, err := ioutil.ReadDir(origin)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			pluginDir := filepath.Join(origin, entry.Name())
+			// Plugin directories are often symbolic links to where they were unpacked
+			if info, err := os.Stat(pluginDir); err != nil || !info.IsDir() {
+				continue
+			}
+			path := filepath.Join(pluginDir, manifest)
+			if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if i, ok := found[entry.Name()]; ok {
+				plugins[i].Shadowed = append(plugins[i].Shadowed, pluginDir)
+				continue
+			}
+			found[entry.Name()] = len(plugins)
+			plugins = append(plugins, Plugin{
+				Name:     entry.Name(),
+				Dir:      pluginDir,
+				Manifest: path,
+				Origin:   origin,
+				User:     dir == userDir,
+			})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins, nil
+}
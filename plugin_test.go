@@ -0,0 +1,116 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupPlugins(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataDirs := strings.Join([]string{filepath.Join(tmp, "local"), filepath.Join(tmp, "share")}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "home")) // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                   // nolint: errcheck
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func writePlugin(t *testing.T, dir, manifest string) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if manifest == "" {
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, manifest), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXDG_DiscoverPlugins(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupPlugins(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	plugins := func(base string) string {
+		return filepath.Join(tmp, base, "OpenPeeDeeP", "XDG", "plugins")
+	}
+	writePlugin(t, filepath.Join(plugins("home"), "spell"), DefaultPluginManifest)
+	writePlugin(t, filepath.Join(plugins("local"), "spell"), DefaultPluginManifest)
+	writePlugin(t, filepath.Join(plugins("share"), "spell"), DefaultPluginManifest)
+	writePlugin(t, filepath.Join(plugins("local"), "git"), DefaultPluginManifest)
+	writePlugin(t, filepath.Join(plugins("share"), "git"), "")
+	writePlugin(t, filepath.Join(plugins("share"), "broken"), "")
+	writePlugin(t, filepath.Join(plugins("share"), "lint"), DefaultPluginManifest)
+	assert.NoError(ioutil.WriteFile(filepath.Join(plugins("share"), "README"), nil, 0600))
+
+	found, err := x.DiscoverPlugins("plugins")
+	assert.NoError(err)
+	assert.Equal([]Plugin{
+		{
+			Name:     "git",
+			Dir:      filepath.Join(plugins("local"), "git"),
+			Manifest: filepath.Join(plugins("local"), "git", DefaultPluginManifest),
+			Origin:   plugins("local"),
+		},
+		{
+			Name:     "lint",
+			Dir:      filepath.Join(plugins("share"), "lint"),
+			Manifest: filepath.Join(plugins("share"), "lint", DefaultPluginManifest),
+			Origin:   plugins("share"),
+		},
+		{
+			Name:     "spell",
+			Dir:      filepath.Join(plugins("home"), "spell"),
+			Manifest: filepath.Join(plugins("home"), "spell", DefaultPluginManifest),
+			Origin:   plugins("home"),
+			User:     true,
+			Shadowed: []string{filepath.Join(plugins("local"), "spell"), filepath.Join(plugins("share"), "spell")},
+		},
+	}, found)
+
+	x.PluginManifest = "manifest.ini"
+	found, err = x.DiscoverPlugins("plugins")
+	assert.NoError(err)
+	assert.Empty(found)
+
+	found, err = x.DiscoverPlugins("missing")
+	assert.NoError(err)
+	assert.Empty(found)
+
+	_, err = x.DiscoverPlugins("../plugins")
+	assert.IsType(&UnsafeNameError{}, err)
+}
+
+func TestXDG_DiscoverPluginsSymlink(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupPlugins(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	origin := filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG", "plugins")
+	writePlugin(t, filepath.Join(tmp, "unpacked", "spell-1.0"), DefaultPluginManifest)
+	assert.NoError(os.MkdirAll(origin, 0700))
+	if err := os.Symlink(filepath.Join(tmp, "unpacked", "spell-1.0"), filepath.Join(origin, "spell")); err != nil {
+		t.Skip(err)
+	}
+
+	found, err := x.DiscoverPlugins("plugins")
+	assert.NoError(err)
+	if assert.Len(found, 1) {
+		assert.Equal("spell", found[0].Name)
+		assert.Equal(filepath.Join(origin, "spell", DefaultPluginManifest), found[0].Manifest)
+		assert.True(found[0].User)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+var executable = os.Executable
+
+// portableRoot returns the directory of the running executable when portable mode is on.
+// The second value is PortableEnv when it was the environment that turned portable mode on.
+func (x *XDG) portableRoot() (string, string) {
+	if !x.Portable && x.PortableMarker == "" && x.PortableEnv == "" {
+		return "", ""
+	}
+	exe, err := executable()
+	if err != nil {
+		return "", ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	root := filepath.Dir(exe)
+	switch {
+	case x.Portable:
+		return root, ""
+	case x.PortableEnv != "" && os.Getenv(x.PortableEnv) != "":
+		return root, x.PortableEnv
+	case x.PortableMarker != "":
+		if _, err := os.Stat(filepath.Join(root, x.PortableMarker)); err == nil {
+			return root, ""
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type portableTestCase struct {
+	name     string
+	portable bool
+	marker   string
+	env      string
+	envVal   string
+	expected Explanation
+}
+
+func TestXDG_Portable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xdg-portable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	dir, _ = filepath.EvalSymlinks(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "portable.txt"), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { executable = os.Executable }()
+	executable = func() (string, error) {
+		return filepath.Join(dir, "app"), nil
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Clean("/xdg")) // nolint: errcheck
+
+	testCases := []portableTestCase{
+		{"Off", false, "", "", "", Explanation{Config, filepath.Clean("/xdg/OpenPeeDeeP/XDG"), SourceXDG, "XDG_CONFIG_HOME"}},
+		{"Explicit", true, "", "", "", Explanation{Config, filepath.Join(dir, "config"), SourcePortable, ""}},
+		{"Marker", false, "portable.txt", "", "", Explanation{Config, filepath.Join(dir, "config"), SourcePortable, ""}},
+		{"Marker DNE", false, "missing.txt", "", "", Explanation{Config, filepath.Clean("/xdg/OpenPeeDeeP/XDG"), SourceXDG, "XDG_CONFIG_HOME"}},
+		{"Env", false, "", "XDG_TEST_PORTABLE", "1", Explanation{Config, filepath.Join(dir, "config"), SourcePortable, "XDG_TEST_PORTABLE"}},
+		{"Env Empty", false, "", "XDG_TEST_PORTABLE", "", Explanation{Config, filepath.Clean("/xdg/OpenPeeDeeP/XDG"), SourceXDG, "XDG_CONFIG_HOME"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			if tc.env != "" {
+				os.Setenv(tc.env, tc.envVal) // nolint: errcheck
+				defer os.Unsetenv(tc.env)    // nolint: errcheck
+			}
+			x := New("OpenPeeDeeP", "XDG")
+			x.Portable = tc.portable
+			x.PortableMarker = tc.marker
+			x.PortableEnv = tc.env
+
+			assert.Equal(tc.expected, x.Explain(Config))
+			assert.Equal(tc.expected.Path, x.ConfigHome())
+		})
+	}
+}
+
+func TestXDG_PortableOverride(t *testing.T) {
+	assert := assert.New(t)
+	defer func() { executable = os.Executable }()
+	executable = func() (string, error) {
+		return filepath.Clean("/opt/app/app"), nil
+	}
+	os.Setenv("FOO_DATA_HOME", filepath.Clean("/app")) // nolint: errcheck
+	defer os.Unsetenv("FOO_DATA_HOME")                 // nolint: errcheck
+
+	x := New("OpenPeeDeeP", "XDG")
+	x.Portable = true
+	x.EnvPrefix = "FOO"
+	assert.Equal(filepath.Clean("/app"), x.DataHome())
+	assert.Equal(filepath.Clean("/opt/app/cache"), x.CacheHome())
+}
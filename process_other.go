@@ -0,0 +1,25 @@
+// +build plan9 js wasip1
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"strconv"
+)
+
+// processAlive reports whether a process with the given PID is listed in /proc.
+// Where there is no /proc, such as on js, only the running process is known.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+	return err == nil
+}
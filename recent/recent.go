@@ -0,0 +1,471 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package recent reads and updates the list of recently used files shared by desktop applications.
+//
+// The list is the XBEL file described by https://www.freedesktop.org/wiki/Specifications/desktop-bookmark-spec/
+package recent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenPeeDeeP/xdg"
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+	"github.com/OpenPeeDeeP/xdg/internal/lockfile"
+)
+
+// FileName is the name of the list in DataHome
+const FileName = "recently-used.xbel"
+
+const (
+	bookmarkNS = "http://www.freedesktop.org/standards/desktop-bookmarks"
+	mimeNS     = "http://www.freedesktop.org/standards/shared-mime-info"
+	owner      = "http://freedesktop.org"
+	timeFormat = "2006-01-02T15:04:05.000000Z"
+)
+
+// Bookmark is a recently used file
+type Bookmark struct {
+	// Href is the URI of the file, such as file:///home/user/notes.txt
+	Href        string
+	Title       string
+	Description string
+	Added       time.Time
+	Modified    time.Time
+	Visited     time.Time
+	MimeType    string
+	Groups      []string
+	// Private bookmarks are only shown by the applications that registered them
+	Private      bool
+	Applications []Application
+	// Metadata holds the metadata this package does not interpret, such as the icon of the bookmark
+	// or the metadata of other owners, so that it is written back unchanged
+	Metadata []Metadata
+}
+
+// Metadata is metadata of a bookmark kept as XML
+type Metadata struct {
+	// Owner is who defined the metadata, such as http://freedesktop.org
+	Owner string
+	// XML holds the elements of the metadata. It declares the namespaces it uses
+	// other than the bookmark and mime ones of the XBEL file.
+	XML string
+}
+
+// Application is an application that used a bookmarked file
+type Application struct {
+	Name string
+	// Exec is the command line opening the file, such as 'gedit %u'
+	Exec     string
+	Modified time.Time
+	// Count is how many times the application registered the file
+	Count int
+}
+
+// Path returns the location of the list
+func Path() string {
+	return filepath.Join(xdg.DataHome(), FileName)
+}
+
+type xbelFile struct {
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+}
+
+type xbelBookmark struct {
+	Href     string         `xml:"href,attr"`
+	Added    string         `xml:"added,attr"`
+	Modified string         `xml:"modified,attr"`
+	Visited  string         `xml:"visited,attr"`
+	Title    string         `xml:"title"`
+	Desc     string         `xml:"desc"`
+	Metadata []xbelMetadata `xml:"info>metadata"`
+}
+
+type xbelMetadata struct {
+	Owner    string `xml:"owner,attr"`
+	MimeType struct {
+		Type string `xml:"type,attr"`
+	} `xml:"http://www.freedesktop.org/standards/shared-mime-info mime-type"`
+	Groups       []string          `xml:"http://www.freedesktop.org/standards/desktop-bookmarks groups>group"`
+	Applications []xbelApplication `xml:"http://www.freedesktop.org/standards/desktop-bookmarks applications>application"`
+	Private      *struct{}         `xml:"http://www.freedesktop.org/standards/desktop-bookmarks private"`
+	Other        []rawXML          `xml:",any"`
+}
+
+// UnmarshalXML reads the metadata of freedesktop.org into the fields of m. The elements of other owners are only kept as XML.
+func (m *xbelMetadata) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name == (xml.Name{Local: "owner"}) {
+			m.Owner = attr.Value
+		}
+	}
+	if m.Owner != owner {
+		var other struct {
+			Elements []rawXML `xml:",any"`
+		}
+		err := d.DecodeElement(&other, &start)
+		m.Other = other.Elements
+		return err
+	}
+	type fields xbelMetadata
+	return d.DecodeElement((*fields)(m), &start)
+}
+
+type xbelApplication struct {
+	Name      string `xml:"name,attr"`
+	Exec      string `xml:"exec,attr"`
+	Modified  string `xml:"modified,attr"`
+	Timestamp string `xml:"timestamp,attr"`
+	Count     int    `xml:"count,attr"`
+}
+
+// Parse reads an XBEL file from r.
+// Only the metadata owned by freedesktop.org is read into the fields of the bookmarks, the rest is kept in Metadata.
+func Parse(r io.Reader) ([]Bookmark, error) {
+	var file xbelFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, err
+	}
+	bookmarks := make([]Bookmark, 0, len(file.Bookmarks))
+	for _, xb := range file.Bookmarks {
+		b := Bookmark{
+			Href:        xb.Href,
+			Title:       xb.Title,
+			Description: xb.Desc,
+			Added:       parseTime(xb.Added),
+			Modified:    parseTime(xb.Modified),
+			Visited:     parseTime(xb.Visited),
+		}
+		for _, m := range xb.Metadata {
+			var raw strings.Builder
+			for _, x := range m.Other {
+				raw.WriteString(string(x))
+			}
+			if m.Owner != owner || raw.Len() > 0 {
+				b.Metadata = append(b.Metadata, Metadata{Owner: m.Owner, XML: raw.String()})
+			}
+			if m.Owner != owner {
+				continue
+			}
+			b.MimeType = m.MimeType.Type
+			b.Groups = m.Groups
+			b.Private = m.Private != nil
+			for _, xa := range m.Applications {
+				a := Application{Name: xa.Name, Exec: xa.Exec, Modified: parseTime(xa.Modified), Count: xa.Count}
+				if a.Modified.IsZero() && xa.Timestamp != "" {
+					// Older versions of GTK wrote seconds since the epoch
+					if sec, err := strconv.ParseInt(xa.Timestamp, 10, 64); err == nil {
+						a.Modified = time.Unix(sec, 0).UTC()
+					}
+				}
+				b.Applications = append(b.Applications, a)
+			}
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, nil
+}
+
+func parseTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// Write writes bookmarks to w as an XBEL file in the layout GTK uses
+func Write(w io.Writer, bookmarks []Bookmark) error {
+	bw := bufio.NewWriter(w)
+	p := &printer{w: bw}
+	p.raw(xml.Header)
+	p.raw("<xbel version=\"1.0\"\n")
+	p.raw("      xmlns:bookmark=\"" + bookmarkNS + "\"\n")
+	p.raw("      xmlns:mime=\"" + mimeNS + "\"\n")
+	p.raw(">\n")
+	for _, b := range bookmarks {
+		p.raw("  <bookmark href=\"")
+		p.escape(b.Href)
+		p.raw("\" added=\"" + formatTime(b.Added) + "\" modified=\"" + formatTime(b.Modified) + "\" visited=\"" + formatTime(b.Visited) + "\">\n")
+		if b.Title != "" {
+			p.raw("    <title>")
+			p.escape(b.Title)
+			p.raw("</title>\n")
+		}
+		if b.Description != "" {
+			p.raw("    <desc>")
+			p.escape(b.Description)
+			p.raw("</desc>\n")
+		}
+		p.raw("    <info>\n")
+		p.raw("      <metadata owner=\"" + owner + "\">\n")
+		if b.MimeType != "" {
+			p.raw("        <mime:mime-type type=\"")
+			p.escape(b.MimeType)
+			p.raw("\"/>\n")
+		}
+		if len(b.Groups) > 0 {
+			p.raw("        <bookmark:groups>\n")
+			for _, g := range b.Groups {
+				p.raw("          <bookmark:group>")
+				p.escape(g)
+				p.raw("</bookmark:group>\n")
+			}
+			p.raw("        </bookmark:groups>\n")
+		}
+		if len(b.Applications) > 0 {
+			p.raw("        <bookmark:applications>\n")
+			for _, a := range b.Applications {
+				p.raw("          <bookmark:application name=\"")
+				p.escape(a.Name)
+				p.raw("\" exec=\"")
+				p.escape(a.Exec)
+				p.raw("\" modified=\"" + formatTime(a.Modified) + "\" count=\"" + strconv.Itoa(a.Count) + "\"/>\n")
+			}
+			p.raw("        </bookmark:applications>\n")
+		}
+		if b.Private {
+			p.raw("        <bookmark:private/>\n")
+		}
+		for _, m := range b.Metadata {
+			if m.Owner == owner && m.XML != "" {
+				p.raw("        " + m.XML + "\n")
+			}
+		}
+		p.raw("      </metadata>\n")
+		for _, m := range b.Metadata {
+			if m.Owner == owner {
+				continue
+			}
+			p.raw("      <metadata owner=\"")
+			p.escape(m.Owner)
+			p.raw("\">")
+			if m.XML != "" {
+				p.raw("\n        " + m.XML + "\n      ")
+			}
+			p.raw("</metadata>\n")
+		}
+		p.raw("    </info>\n")
+		p.raw("  </bookmark>\n")
+	}
+	p.raw("</xbel>")
+	if p.err != nil {
+		return p.err
+	}
+	return bw.Flush()
+}
+
+// printer remembers the first error while writing
+type printer struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (p *printer) raw(s string) {
+	if p.err == nil {
+		_, p.err = p.w.WriteString(s)
+	}
+}
+
+func (p *printer) escape(s string) {
+	if p.err == nil {
+		p.err = xml.EscapeText(p.w, []byte(s))
+	}
+}
+
+// rawXML is an element read as XML that declares the namespaces it uses
+type rawXML string
+
+func (r *rawXML) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	tokens := []xml.Token{start.Copy()}
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+	*r = rawXML(encodeTokens(tokens))
+	return nil
+}
+
+// encodeTokens writes the tokens of an element. The decoder resolved the prefixes of the names to
+// namespaces, which are given the prefixes of the XBEL file or declared on the element.
+func encodeTokens(tokens []xml.Token) string {
+	prefixes := map[string]string{
+		"":                                     "",
+		bookmarkNS:                             "bookmark",
+		mimeNS:                                 "mime",
+		"http://www.w3.org/XML/1998/namespace": "xml",
+	}
+	var declared []string
+	prefix := func(space string) {
+		if _, ok := prefixes[space]; !ok {
+			prefixes[space] = "ns" + strconv.Itoa(len(declared)+1)
+			declared = append(declared, space)
+		}
+	}
+	for _, token := range tokens {
+		if start, ok := token.(xml.StartElement); ok {
+			prefix(start.Name.Space)
+			for _, attr := range attributes(start) {
+				prefix(attr.Name.Space)
+			}
+		}
+	}
+	name := func(n xml.Name) string {
+		if p := prefixes[n.Space]; p != "" {
+			return p + ":" + n.Local
+		}
+		return n.Local
+	}
+
+	var buf bytes.Buffer
+	for i := 0; i < len(tokens); i++ {
+		switch token := tokens[i].(type) {
+		case xml.StartElement:
+			buf.WriteString("<" + name(token.Name))
+			if i == 0 {
+				for _, space := range declared {
+					buf.WriteString(" xmlns:" + prefixes[space] + "=\"")
+					xml.EscapeText(&buf, []byte(space)) // nolint: errcheck
+					buf.WriteString("\"")
+				}
+			}
+			for _, attr := range attributes(token) {
+				buf.WriteString(" " + name(attr.Name) + "=\"")
+				xml.EscapeText(&buf, []byte(attr.Value)) // nolint: errcheck
+				buf.WriteString("\"")
+			}
+			if _, empty := tokens[i+1].(xml.EndElement); empty {
+				buf.WriteString("/>")
+				i++
+			} else {
+				buf.WriteString(">")
+			}
+		case xml.EndElement:
+			buf.WriteString("</" + name(token.Name) + ">")
+		case xml.CharData:
+			textEscaper.WriteString(&buf, string(token)) // nolint: errcheck
+		case xml.Comment:
+			buf.WriteString("<!--" + string(token) + "-->")
+		}
+	}
+	return buf.String()
+}
+
+// textEscaper escapes text between elements, leaving line breaks as they are
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// attributes returns the attributes of start other than namespace declarations
+func attributes(start xml.StartElement) []xml.Attr {
+	var attrs []xml.Attr
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "xmlns" && attr.Name != (xml.Name{Local: "xmlns"}) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+// Load reads the list at Path. A missing list is empty.
+func Load() ([]Bookmark, error) {
+	file, err := os.Open(Path())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // nolint: errcheck
+	return Parse(file)
+}
+
+// Update replaces the list at Path with the bookmarks returned by fn.
+// Other processes using this package wait while fn runs, and readers see either the old or the new list.
+func Update(fn func([]Bookmark) []Bookmark) error {
+	path := Path()
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	lock, err := lockfile.Lock(filepath.Join(dir, "."+FileName+".lock"))
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock() // nolint: errcheck
+	bookmarks, err := Load()
+	if err != nil {
+		return err
+	}
+	bookmarks = fn(bookmarks)
+
+	return atomicfile.Write(path, 0600, func(w io.Writer) error {
+		return Write(w, bookmarks)
+	})
+}
+
+// Add records that app used the file at uri, adding a bookmark for it when there is none.
+// The count of app is incremented and the times are set to now. mimeType is only updated when it is set.
+func Add(uri, mimeType string, app Application) error {
+	now := time.Now().UTC()
+	return Update(func(bookmarks []Bookmark) []Bookmark {
+		i := index(bookmarks, uri)
+		if i < 0 {
+			bookmarks = append(bookmarks, Bookmark{Href: uri, Added: now})
+			i = len(bookmarks) - 1
+		}
+		b := &bookmarks[i]
+		b.Modified, b.Visited = now, now
+		if mimeType != "" {
+			b.MimeType = mimeType
+		}
+		for j := range b.Applications {
+			if a := &b.Applications[j]; a.Name == app.Name {
+				a.Exec, a.Modified = app.Exec, now
+				a.Count++
+				return bookmarks
+			}
+		}
+		b.Applications = append(b.Applications, Application{Name: app.Name, Exec: app.Exec, Modified: now, Count: 1})
+		return bookmarks
+	})
+}
+
+// Remove removes the bookmark for uri from the list
+func Remove(uri string) error {
+	return Update(func(bookmarks []Bookmark) []Bookmark {
+		if i := index(bookmarks, uri); i >= 0 {
+			bookmarks = append(bookmarks[:i], bookmarks[i+1:]...)
+		}
+		return bookmarks
+	})
+}
+
+func index(bookmarks []Bookmark, uri string) int {
+	for i, b := range bookmarks {
+		if b.Href == uri {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package recent
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<xbel version="1.0"
+      xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks"
+      xmlns:mime="http://www.freedesktop.org/standards/shared-mime-info"
+>
+  <bookmark href="file:///home/user/notes%20%26%20todo.txt" added="2017-03-01T10:20:30.123456Z" modified="2017-03-02T10:20:30.000000Z" visited="2017-03-02T10:20:30.000000Z">
+    <title>Notes &amp; todo</title>
+    <info>
+      <metadata owner="http://freedesktop.org">
+        <mime:mime-type type="text/plain"/>
+        <bookmark:groups>
+          <bookmark:group>gedit</bookmark:group>
+        </bookmark:groups>
+        <bookmark:applications>
+          <bookmark:application name="gedit" exec="&#39;gedit %u&#39;" modified="2017-03-02T10:20:30.000000Z" count="2"/>
+        </bookmark:applications>
+        <bookmark:private/>
+      </metadata>
+    </info>
+  </bookmark>
+  <bookmark href="file:///home/user/song.ogg" added="2017-03-03T00:00:00.000000Z" modified="2017-03-03T00:00:00.000000Z" visited="2017-03-03T00:00:00.000000Z">
+    <info>
+      <metadata owner="http://freedesktop.org">
+        <mime:mime-type type="audio/ogg"/>
+        <bookmark:applications>
+          <bookmark:application name="player" exec="&#39;player %f&#39;" modified="2017-03-03T00:00:00.000000Z" count="1"/>
+        </bookmark:applications>
+      </metadata>
+    </info>
+  </bookmark>
+</xbel>`
+
+func TestParse(t *testing.T) {
+	assert := assert.New(t)
+	bookmarks, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(err) || !assert.Len(bookmarks, 2) {
+		return
+	}
+	b := bookmarks[0]
+	assert.Equal("file:///home/user/notes%20%26%20todo.txt", b.Href)
+	assert.Equal("Notes & todo", b.Title)
+	assert.Equal(time.Date(2017, 3, 1, 10, 20, 30, 123456000, time.UTC), b.Added)
+	assert.Equal("text/plain", b.MimeType)
+	assert.Equal([]string{"gedit"}, b.Groups)
+	assert.True(b.Private)
+	assert.Equal([]Application{{Name: "gedit", Exec: "'gedit %u'", Modified: time.Date(2017, 3, 2, 10, 20, 30, 0, time.UTC), Count: 2}}, b.Applications)
+	assert.False(bookmarks[1].Private)
+
+	var buf bytes.Buffer
+	assert.NoError(Write(&buf, bookmarks))
+	assert.Equal(sample, buf.String())
+}
+
+func TestParseOldTimestamp(t *testing.T) {
+	assert := assert.New(t)
+	bookmarks, err := Parse(strings.NewReader(`<xbel xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks">
+<bookmark href="file:///a"><info><metadata owner="http://freedesktop.org"><bookmark:applications>
+<bookmark:application name="old" exec="old %f" timestamp="1488364800" count="3"/>
+</bookmark:applications></metadata><metadata owner="http://example.com"><bookmark:private/></metadata></info></bookmark></xbel>`))
+	if assert.NoError(err) && assert.Len(bookmarks, 1) {
+		assert.Equal([]Application{{Name: "old", Exec: "old %f", Modified: time.Unix(1488364800, 0).UTC(), Count: 3}}, bookmarks[0].Applications)
+		assert.False(bookmarks[0].Private)
+	}
+	_, err = Parse(strings.NewReader("<xbel><bookmark>"))
+	assert.Error(err)
+}
+
+const foreign = `<?xml version="1.0" encoding="UTF-8"?>
+<xbel version="1.0"
+      xmlns:bookmark="http://www.freedesktop.org/standards/desktop-bookmarks"
+      xmlns:mime="http://www.freedesktop.org/standards/shared-mime-info"
+      xmlns:kde="http://www.kde.org"
+>
+  <bookmark href="file:///home/user/photo.png" added="2017-03-01T10:20:30.000000Z" modified="2017-03-01T10:20:30.000000Z" visited="2017-03-01T10:20:30.000000Z">
+    <info>
+      <metadata owner="http://freedesktop.org">
+        <mime:mime-type type="image/png"/>
+        <bookmark:icon href="file:///home/user/.icons/photo.png" type="image/png"/>
+        <bookmark:applications>
+          <bookmark:application name="viewer" exec="&#39;viewer %u&#39;" modified="2017-03-01T10:20:30.000000Z" count="1"/>
+        </bookmark:applications>
+      </metadata>
+      <metadata owner="http://www.kde.org">
+        <kde:activities>
+          <kde:activity id="a &amp; b">work &lt;3</kde:activity>
+        </kde:activities>
+        <bookmark:private/>
+      </metadata>
+    </info>
+  </bookmark>
+</xbel>`
+
+func TestParseForeignMetadata(t *testing.T) {
+	assert := assert.New(t)
+	bookmarks, err := Parse(strings.NewReader(foreign))
+	if !assert.NoError(err) || !assert.Len(bookmarks, 1) {
+		return
+	}
+	b := bookmarks[0]
+	assert.Equal("image/png", b.MimeType)
+	assert.False(b.Private)
+	assert.Len(b.Applications, 1)
+	assert.Equal([]Metadata{
+		{Owner: "http://freedesktop.org", XML: `<bookmark:icon href="file:///home/user/.icons/photo.png" type="image/png"/>`},
+		{Owner: "http://www.kde.org", XML: `<ns1:activities xmlns:ns1="http://www.kde.org">
+          <ns1:activity id="a &amp; b">work &lt;3</ns1:activity>
+        </ns1:activities><bookmark:private/>`},
+	}, b.Metadata)
+
+	var buf bytes.Buffer
+	if !assert.NoError(Write(&buf, bookmarks)) {
+		return
+	}
+	written, err := Parse(bytes.NewReader(buf.Bytes()))
+	assert.NoError(err)
+	assert.Equal(bookmarks, written)
+	assert.Contains(buf.String(), `<metadata owner="http://www.kde.org">`)
+
+	// Add keeps the metadata of the bookmark it updates
+	defer standupRecent(t)()
+	assert.NoError(ioutil.WriteFile(Path(), []byte(foreign), 0600))
+	assert.NoError(Add(b.Href, "", Application{Name: "viewer", Exec: "'viewer %u'"}))
+	updated, err := Load()
+	if assert.NoError(err) && assert.Len(updated, 1) {
+		assert.Equal(b.Metadata, updated[0].Metadata)
+		assert.Equal(2, updated[0].Applications[0].Count)
+	}
+}
+
+func standupRecent(t *testing.T) func() {
+	tmp, err := ioutil.TempDir("", "recent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_DATA_HOME", tmp) // nolint: errcheck
+	return func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	assert := assert.New(t)
+	defer standupRecent(t)()
+	bookmarks, err := Load()
+	assert.NoError(err)
+	assert.Empty(bookmarks)
+
+	editor := Application{Name: "editor", Exec: "'editor %u'"}
+	assert.NoError(Add("file:///a.txt", "text/plain", editor))
+	assert.NoError(Add("file:///b.md", "text/markdown", editor))
+	assert.NoError(Add("file:///a.txt", "", editor))
+	assert.NoError(Add("file:///a.txt", "", Application{Name: "viewer", Exec: "'viewer %f'"}))
+
+	bookmarks, err = Load()
+	if !assert.NoError(err) || !assert.Len(bookmarks, 2) {
+		return
+	}
+	a := bookmarks[0]
+	assert.Equal("file:///a.txt", a.Href)
+	assert.Equal("text/plain", a.MimeType)
+	if assert.Len(a.Applications, 2) {
+		assert.Equal("editor", a.Applications[0].Name)
+		assert.Equal(2, a.Applications[0].Count)
+		assert.Equal(1, a.Applications[1].Count)
+	}
+	assert.False(a.Added.After(a.Modified))
+	info, err := os.Stat(Path())
+	if assert.NoError(err) {
+		assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+
+	assert.NoError(Remove("file:///a.txt"))
+	assert.NoError(Remove("file:///missing"))
+	bookmarks, err = Load()
+	assert.NoError(err)
+	if assert.Len(bookmarks, 1) {
+		assert.Equal("file:///b.md", bookmarks[0].Href)
+	}
+}
+
+func TestAddConcurrent(t *testing.T) {
+	switch runtime.GOOS {
+	case "aix", "solaris", "illumos", "plan9", "js", "wasip1":
+		t.Skip("locks do not exclude the process holding them on", runtime.GOOS)
+	}
+	assert := assert.New(t)
+	defer standupRecent(t)()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(Add("file:///shared.txt", "text/plain", Application{Name: "editor", Exec: "editor %u"}))
+		}()
+	}
+	wg.Wait()
+	bookmarks, err := Load()
+	assert.NoError(err)
+	if assert.Len(bookmarks, 1) && assert.Len(bookmarks[0].Applications, 1) {
+		assert.Equal(10, bookmarks[0].Applications[0].Count)
+	}
+	files, err := ioutil.ReadDir(filepath.Dir(Path()))
+	assert.NoError(err)
+	assert.Len(files, 2)
+}
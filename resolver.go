@@ -0,0 +1,156 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"sync"
+	"time"
+)
+
+// maxLookups is the number of lookups a Resolver remembers
+const maxLookups = 1024
+
+// Resolver answers queries from directories resolved once, for code that looks files up often.
+// The results of lookups, found or not, can be remembered too. A Resolver is safe for concurrent use.
+type Resolver struct {
+	x   *XDG
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	paths   *Paths
+	search  [Runtime + 1][]string
+	lookups map[lookupKey]lookup
+	// generation tells lookups that raced with Invalidate apart
+	generation uint64
+}
+
+type lookupKey struct {
+	kind     Kind
+	filename string
+}
+
+type lookup struct {
+	path    string
+	err     error
+	expires time.Time
+}
+
+// Resolver returns a Resolver for this specific application.
+// A lookup is remembered for ttl, or until Invalidate when ttl is negative. Nothing is remembered when ttl is 0,
+// so every query checks the file system, but the directories are still only resolved once.
+// At most 1024 lookups are remembered: once there are that many, the expired ones are forgotten,
+// or an arbitrary one when none has expired.
+func (x *XDG) Resolver(ttl time.Duration) *Resolver {
+	r := &Resolver{x: x, ttl: ttl}
+	r.resolve()
+	return r
+}
+
+func (r *Resolver) resolve() {
+	r.paths = r.x.Resolve()
+	for kind := range r.search {
+		r.search[kind] = r.paths.SearchDirs(Kind(kind))
+	}
+	r.lookups = make(map[lookupKey]lookup)
+	r.generation++
+}
+
+// Invalidate forgets every remembered lookup and resolves the directories again,
+// picking up changes to the environment
+func (r *Resolver) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolve()
+}
+
+// Paths returns the directories the Resolver searches, as resolved when it was created or last invalidated
+func (r *Resolver) Paths() *Paths {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.paths
+}
+
+// Query looks for the given filename in XDG paths for files of kind, like (*XDG).Query.
+// Returns an empty string if one was not found.
+func (r *Resolver) Query(kind Kind, filename string) (string, error) {
+	key := lookupKey{kind, filename}
+	r.mu.RLock()
+	cached, ok := r.lookups[key]
+	var dirs []string
+	if kind >= 0 && int(kind) < len(r.search) {
+		dirs = r.search[kind]
+	}
+	generation := r.generation
+	r.mu.RUnlock()
+	now := time.Now()
+	if ok && (r.ttl < 0 || now.Before(cached.expires)) {
+		return cached.path, cached.err
+	}
+
+	var result lookup
+	if len(dirs) == 0 {
+		result.err = ErrUnsupported
+	} else {
+		result.path, result.err = r.x.lookup(kind, filename, dirs)
+	}
+	if r.ttl != 0 {
+		result.expires = now.Add(r.ttl)
+		r.mu.Lock()
+		if r.generation == generation {
+			if _, ok := r.lookups[key]; !ok && len(r.lookups) >= maxLookups {
+				r.evict(now)
+			}
+			r.lookups[key] = result
+		}
+		r.mu.Unlock()
+	}
+	return result.path, result.err
+}
+
+// evict forgets the expired lookups, or an arbitrary one when none has expired
+func (r *Resolver) evict(now time.Time) {
+	evicted := false
+	for key, cached := range r.lookups {
+		if r.ttl > 0 && !now.Before(cached.expires) {
+			delete(r.lookups, key)
+			evicted = true
+		}
+	}
+	if evicted {
+		return
+	}
+	for key := range r.lookups {
+		delete(r.lookups, key)
+		return
+	}
+}
+
+// QueryData looks for the given filename in XDG paths for data files.
+// Returns an empty string if one was not found.
+func (r *Resolver) QueryData(filename string) string {
+	path, _ := r.Query(Data, filename)
+	return path
+}
+
+// QueryConfig looks for the given filename in XDG paths for config files.
+// Returns an empty string if one was not found.
+func (r *Resolver) QueryConfig(filename string) string {
+	path, _ := r.Query(Config, filename)
+	return path
+}
+
+// QueryCache looks for the given filename in XDG paths for cache files.
+// Returns an empty string if one was not found.
+func (r *Resolver) QueryCache(filename string) string {
+	path, _ := r.Query(Cache, filename)
+	return path
+}
+
+// QueryState looks for the given filename in XDG paths for state files.
+// Returns an empty string if one was not found.
+func (r *Resolver) QueryState(filename string) string {
+	path, _ := r.Query(State, filename)
+	return path
+}
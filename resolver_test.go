@@ -0,0 +1,177 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupResolver(t testing.TB) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-resolver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataDirs := strings.Join([]string{filepath.Join(tmp, "local"), filepath.Join(tmp, "share")}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "home")) // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                   // nolint: errcheck
+	dir := filepath.Join(tmp, "share", "OpenPeeDeeP", "XDG")
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "page.tmpl"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestResolver_Query(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		// remembered is whether changes to the file system stay hidden
+		remembered bool
+	}{
+		{"No Memoization", 0, false},
+		{"TTL", time.Hour, true},
+		{"Until Invalidate", -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := assert.New(t)
+			tmp, teardown := standupResolver(t)
+			defer teardown()
+			x := New("OpenPeeDeeP", "XDG")
+			r := x.Resolver(tt.ttl)
+			share := filepath.Join(tmp, "share", "OpenPeeDeeP", "XDG", "page.tmpl")
+			home := filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG", "page.tmpl")
+
+			assert.Equal(x.Resolve(), r.Paths())
+			assert.Equal(share, r.QueryData("page.tmpl"))
+			assert.Equal("", r.QueryData("missing.tmpl"))
+			assert.NoError(os.MkdirAll(filepath.Dir(home), 0700))
+			assert.NoError(ioutil.WriteFile(home, nil, 0600))
+			assert.NoError(ioutil.WriteFile(filepath.Join(filepath.Dir(home), "missing.tmpl"), nil, 0600))
+			if tt.remembered {
+				assert.Equal(share, r.QueryData("page.tmpl"))
+				assert.Equal("", r.QueryData("missing.tmpl"))
+			}
+			r.Invalidate()
+			assert.Equal(home, r.QueryData("page.tmpl"))
+			assert.Equal(filepath.Join(filepath.Dir(home), "missing.tmpl"), r.QueryData("missing.tmpl"))
+		})
+	}
+}
+
+func TestResolver_TTL(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupResolver(t)
+	defer teardown()
+	r := New("OpenPeeDeeP", "XDG").Resolver(time.Millisecond)
+	path := filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG", "new.tmpl")
+	assert.Equal("", r.QueryData("new.tmpl"))
+	assert.NoError(os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(ioutil.WriteFile(path, nil, 0600))
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(path, r.QueryData("new.tmpl"))
+}
+
+func TestResolver_Evict(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupResolver(t)
+	defer teardown()
+	r := New("OpenPeeDeeP", "XDG").Resolver(-1)
+	for i := 0; i < maxLookups+10; i++ {
+		r.QueryData(strconv.Itoa(i) + ".tmpl")
+	}
+	assert.Equal(maxLookups, len(r.lookups))
+
+	r = New("OpenPeeDeeP", "XDG").Resolver(time.Millisecond)
+	for i := 0; i < maxLookups; i++ {
+		r.QueryData(strconv.Itoa(i) + ".tmpl")
+	}
+	time.Sleep(5 * time.Millisecond)
+	r.QueryData("page.tmpl")
+	assert.Equal(1, len(r.lookups))
+}
+
+func TestResolver_Invalidate(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupResolver(t)
+	defer teardown()
+	r := New("OpenPeeDeeP", "XDG").Resolver(-1)
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "other")) // nolint: errcheck
+	assert.Equal(filepath.Join(tmp, "home", "OpenPeeDeeP", "XDG"), r.Paths().Home(Data))
+	r.Invalidate()
+	assert.Equal(filepath.Join(tmp, "other", "OpenPeeDeeP", "XDG"), r.Paths().Home(Data))
+}
+
+func TestResolver_QueryErrors(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupResolver(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	x.SafeNames = true
+	r := x.Resolver(-1)
+	_, err := r.Query(Data, filepath.Join("..", "page.tmpl"))
+	assert.IsType(&UnsafeNameError{}, err)
+	_, err = r.Query(Kind(42), "page.tmpl")
+	assert.Equal(ErrUnsupported, err)
+}
+
+func TestResolver_QueryAllocs(t *testing.T) {
+	_, teardown := standupResolver(t)
+	defer teardown()
+	r := New("OpenPeeDeeP", "XDG").Resolver(-1)
+	r.QueryData("page.tmpl")
+	r.QueryData("missing.tmpl")
+	allocs := testing.AllocsPerRun(100, func() {
+		r.QueryData("page.tmpl")
+		r.QueryData("missing.tmpl")
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkXDG_QueryData(b *testing.B) {
+	_, teardown := standupResolver(b)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x.QueryData("page.tmpl")
+	}
+}
+
+func BenchmarkResolver_QueryData(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		ttl  time.Duration
+	}{
+		{"NoMemoization", 0},
+		{"TTL", time.Minute},
+		{"UntilInvalidate", -1},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			_, teardown := standupResolver(b)
+			defer teardown()
+			r := New("OpenPeeDeeP", "XDG").Resolver(bm.ttl)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				r.QueryData("page.tmpl")
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// InsecureDirError is returned when a directory that should be private to the user is not
+type InsecureDirError struct {
+	Dir    string
+	Reason string
+}
+
+func (e *InsecureDirError) Error() string {
+	return "xdg: " + e.Dir + " is not private: " + e.Reason
+}
+
+// RuntimeDir returns the location that should be used for user specific runtime files such as sockets and locks.
+// There is no default, it returns an empty string when XDG_RUNTIME_DIR is not set.
+func RuntimeDir() string {
+	return os.Getenv("XDG_RUNTIME_DIR")
+}
+
+// RuntimeDir returns the location that should be used for runtime files for this specific application, creating it when it is missing.
+// When XDG_RUNTIME_DIR is not set, or is not a directory only the user can access, a private directory
+// in the temporary directory is used instead. An *InsecureDirError is returned when that directory
+// was created by someone else. With System set, the directory is below /run and readable by everyone.
+func (x *XDG) RuntimeDir() (string, error) {
+	dir := x.home(Runtime)
+	exp := x.Explain(Runtime)
+	if x.System && exp.Source == SourceDefault {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", err
+		}
+		return dir, nil
+	}
+	if exp.Source == SourceDefault || exp.Source == SourceXDG && checkPrivate(RuntimeDir()) != nil {
+		base, err := privateTempDir(os.TempDir())
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(base, x.name(Runtime))
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// systemRuntimeDir returns the directory holding the runtime files of system services on goos
+func systemRuntimeDir(goos string) string {
+	switch goos {
+	case "linux", "android":
+		return filepath.Join(string(filepath.Separator), "run")
+	case "windows":
+		return os.Getenv("PROGRAMDATA")
+	}
+	return filepath.Join(string(filepath.Separator), "var", "run")
+}
+
+// privateTempDir returns a directory in tmp private to the user, creating it when it is missing
+func privateTempDir(tmp string) (string, error) {
+	name := "xdg-runtime"
+	if uid := os.Getuid(); uid >= 0 {
+		name += "-" + strconv.Itoa(uid)
+	}
+	dir := filepath.Join(tmp, name)
+	if err := os.Mkdir(dir, 0700); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+	return dir, checkPrivate(dir)
+}
+
+// checkPrivate returns an *InsecureDirError unless dir is a directory owned by the user that nobody else can access
+func checkPrivate(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		return &InsecureDirError{Dir: dir, Reason: "it is a symbolic link"}
+	case !info.IsDir():
+		return &InsecureDirError{Dir: dir, Reason: "it is not a directory"}
+	case !ownedByUser(info):
+		return &InsecureDirError{Dir: dir, Reason: "it is owned by another user"}
+	case privatePerm && info.Mode().Perm()&0077 != 0:
+		return &InsecureDirError{Dir: dir, Reason: "its permissions are " + info.Mode().Perm().String()}
+	}
+	return nil
+}
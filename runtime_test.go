@@ -0,0 +1,111 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// standupRuntime points XDG_RUNTIME_DIR and the temporary directory into a new directory
+func standupRuntime(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "xdg-runtime-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpEnv := "TMPDIR"
+	if runtime.GOOS == "windows" {
+		tmpEnv = "TMP"
+	}
+	oldTmp := os.Getenv(tmpEnv)
+	os.Setenv(tmpEnv, filepath.Join(tmp, "tmp"))            // nolint: errcheck
+	os.Setenv("XDG_RUNTIME_DIR", filepath.Join(tmp, "run")) // nolint: errcheck
+	if err = os.MkdirAll(filepath.Join(tmp, "tmp"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(filepath.Join(tmp, "run"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return tmp, func() {
+		os.Setenv(tmpEnv, oldTmp)      // nolint: errcheck
+		os.Unsetenv("XDG_RUNTIME_DIR") // nolint: errcheck
+		os.RemoveAll(tmp)              // nolint: errcheck
+	}
+}
+
+func fallbackName() string {
+	if uid := os.Getuid(); uid >= 0 {
+		return "xdg-runtime-" + strconv.Itoa(uid)
+	}
+	return "xdg-runtime"
+}
+
+func TestXDG_RuntimeDir(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	assert.Equal(filepath.Join(tmp, "run"), RuntimeDir())
+	dir, err := x.RuntimeDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG"), dir)
+	info, err := os.Stat(dir)
+	if assert.NoError(err) {
+		assert.True(info.IsDir())
+	}
+
+	os.Unsetenv("XDG_RUNTIME_DIR") // nolint: errcheck
+	assert.Equal("", RuntimeDir())
+	dir, err = x.RuntimeDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "tmp", fallbackName(), "OpenPeeDeeP", "XDG"), dir)
+	if privatePerm {
+		info, err = os.Stat(filepath.Dir(filepath.Dir(dir)))
+		if assert.NoError(err) {
+			assert.Equal(os.FileMode(0700), info.Mode().Perm())
+		}
+	}
+}
+
+func TestXDG_RuntimeDirInsecure(t *testing.T) {
+	if !privatePerm {
+		t.Skip("permissions are not checked on", runtime.GOOS)
+	}
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	assert.NoError(os.Chmod(filepath.Join(tmp, "run"), 0755))
+	dir, err := x.RuntimeDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "tmp", fallbackName(), "OpenPeeDeeP", "XDG"), dir)
+
+	assert.NoError(os.Chmod(filepath.Join(tmp, "tmp", fallbackName()), 0777))
+	_, err = x.RuntimeDir()
+	if assert.IsType(&InsecureDirError{}, err) {
+		assert.Equal(filepath.Join(tmp, "tmp", fallbackName()), err.(*InsecureDirError).Dir)
+	}
+}
+
+func TestXDG_RuntimeDirOverride(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	os.Setenv("FOO_RUNTIME_DIR", filepath.Join(tmp, "app")) // nolint: errcheck
+	defer os.Unsetenv("FOO_RUNTIME_DIR")                    // nolint: errcheck
+	x := New("OpenPeeDeeP", "XDG")
+	x.EnvPrefix = "FOO"
+	dir, err := x.RuntimeDir()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "app"), dir)
+}
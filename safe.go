@@ -0,0 +1,87 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// UnsafeNameError is returned when a file name escapes the directory it is looked up in
+type UnsafeNameError struct {
+	Name string
+	Dir  string
+	// Symlink is true when the name only escapes after following symbolic links
+	Symlink bool
+}
+
+func (e *UnsafeNameError) Error() string {
+	how := ""
+	if e.Symlink {
+		how = " through a symbolic link"
+	}
+	return "xdg: " + strconv.Quote(e.Name) + " escapes " + e.Dir + how
+}
+
+// escapes reports whether name lexically points outside of the directory it is joined to
+func escapes(name string) bool {
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, string(filepath.Separator)) {
+		return true
+	}
+	name = filepath.Clean(name)
+	return name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator))
+}
+
+// plainName reports whether name is a single path element naming a file in the directory it is joined to
+func plainName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// within reports whether path is inside dir once all symbolic links are followed
+func within(dir, path string) (bool, error) {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(realDir, realPath)
+	if err != nil {
+		return false, nil
+	}
+	return !escapes(rel), nil
+}
+
+func returnSafeExist(filename string, dirs []string) (string, error) {
+	if escapes(filename) {
+		dir := ""
+		if len(dirs) > 0 {
+			dir = dirs[0]
+		}
+		return "", &UnsafeNameError{Name: filename, Dir: dir}
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, filename)
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		ok, err := within(dir, path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", &UnsafeNameError{Name: filename, Dir: dir, Symlink: true}
+		}
+		return path, nil
+	}
+	return "", nil
+}
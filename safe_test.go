@@ -0,0 +1,101 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type safeTestCase struct {
+	name     string
+	filename string
+	expected string
+	symlink  bool
+	unsafe   bool
+}
+
+func TestXDG_SafeNames(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "xdg-safe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	tmp, _ = filepath.EvalSymlinks(tmp)
+	appDir := filepath.Join(tmp, "config", "OpenPeeDeeP", "XDG")
+	if err = os.MkdirAll(filepath.Join(appDir, "sub"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{filepath.Join(appDir, "app.conf"), filepath.Join(appDir, "sub", "sub.conf"), filepath.Join(tmp, "secret")} {
+		if err = ioutil.WriteFile(name, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = os.Symlink(filepath.Join(tmp, "secret"), filepath.Join(appDir, "link.conf")); err != nil {
+		t.Skip("symbolic links are not supported:", err)
+	}
+	if err = os.Symlink(filepath.Join(appDir, "app.conf"), filepath.Join(appDir, "inner.conf")); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config")) // nolint: errcheck
+	os.Setenv("XDG_CONFIG_DIRS", filepath.Join(tmp, "none"))   // nolint: errcheck
+
+	testCases := []safeTestCase{
+		{"Plain", "app.conf", filepath.Join(appDir, "app.conf"), false, false},
+		{"Nested", filepath.Join("sub", "sub.conf"), filepath.Join(appDir, "sub", "sub.conf"), false, false},
+		{"Nested Parent", filepath.Join("sub", "..", "app.conf"), filepath.Join(appDir, "app.conf"), false, false},
+		{"DNE", "missing.conf", "", false, false},
+		{"Inner Symlink", "inner.conf", filepath.Join(appDir, "inner.conf"), false, false},
+		{"Parent", filepath.Join("..", "..", "..", "secret"), "", false, true},
+		{"Absolute", filepath.Join(tmp, "secret"), "", false, true},
+		{"Symlink", "link.conf", "", true, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			x := New("OpenPeeDeeP", "XDG")
+			x.SafeNames = true
+
+			actual, err := x.Query(Config, tc.filename)
+			assert.Equal(tc.expected, actual)
+			assert.Equal(tc.expected, x.QueryConfig(tc.filename))
+			if !tc.unsafe {
+				assert.NoError(err)
+				return
+			}
+			if assert.IsType(&UnsafeNameError{}, err) {
+				unsafeErr := err.(*UnsafeNameError)
+				assert.Equal(tc.filename, unsafeErr.Name)
+				assert.Equal(appDir, unsafeErr.Dir)
+				assert.Equal(tc.symlink, unsafeErr.Symlink)
+			}
+		})
+	}
+}
+
+func TestXDG_UnsafeNames(t *testing.T) {
+	assert := assert.New(t)
+	tmp, err := ioutil.TempDir("", "xdg-safe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp) // nolint: errcheck
+	if err = os.MkdirAll(filepath.Join(tmp, "config", "OpenPeeDeeP", "XDG"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(tmp, "secret"), nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config")) // nolint: errcheck
+
+	x := New("OpenPeeDeeP", "XDG")
+	actual, err := x.Query(Config, filepath.Join("..", "..", "..", "secret"))
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "secret"), actual)
+}
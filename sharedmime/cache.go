@@ -0,0 +1,179 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharedmime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+)
+
+const (
+	cacheMajor         = 1
+	cacheHeaderSize    = 40
+	cacheCaseSensitive = 0x100
+	nodeSize           = 12
+	matchSize          = 16
+	matchletSize       = 32
+)
+
+// cache is the binary mime.cache file written by update-mime-database
+type cache struct {
+	data []byte
+	err  error
+}
+
+// readCache returns the mime.cache file at path when it exists and has a version this package reads
+func readCache(path string) (*cache, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil || len(data) < cacheHeaderSize || binary.BigEndian.Uint16(data) != cacheMajor {
+		return nil, false
+	}
+	return &cache{data: data}, true
+}
+
+// word returns the 32 bit word at offset, recording an error when it is out of bounds
+func (c *cache) word(offset uint32) uint32 {
+	if uint64(offset)+4 > uint64(len(c.data)) {
+		c.err = ErrFormat
+		return 0
+	}
+	return binary.BigEndian.Uint32(c.data[offset:])
+}
+
+// str returns the NUL terminated string at offset
+func (c *cache) str(offset uint32) string {
+	if uint64(offset) >= uint64(len(c.data)) {
+		c.err = ErrFormat
+		return ""
+	}
+	end := bytes.IndexByte(c.data[offset:], 0)
+	if end < 0 {
+		c.err = ErrFormat
+		return ""
+	}
+	return string(c.data[offset : offset+uint32(end)])
+}
+
+// bytes returns the n bytes at offset
+func (c *cache) bytes(offset, n uint32) []byte {
+	if uint64(offset)+uint64(n) > uint64(len(c.data)) {
+		c.err = ErrFormat
+		return nil
+	}
+	return append([]byte(nil), c.data[offset:offset+n]...)
+}
+
+// count returns the number of entries of the given size in the list at offset, guarding against corrupt counts
+func (c *cache) count(offset, size uint32) uint32 {
+	n := c.word(offset)
+	if uint64(n)*uint64(size) > uint64(len(c.data)) {
+		c.err = ErrFormat
+		return 0
+	}
+	return n
+}
+
+// load adds the content of the cache to db
+func (c *cache) load(db *Database) error {
+	aliases, parents := c.word(4), c.word(8)
+	literals, suffixes, globs, magic := c.word(12), c.word(16), c.word(20), c.word(24)
+
+	for i, n := uint32(0), c.count(aliases, 8); i < n && c.err == nil; i++ {
+		entry := aliases + 4 + i*8
+		db.aliases[c.str(c.word(entry))] = c.str(c.word(entry + 4))
+	}
+	for i, n := uint32(0), c.count(parents, 8); i < n && c.err == nil; i++ {
+		entry := parents + 4 + i*8
+		mimeType, list := c.str(c.word(entry)), c.word(entry+4)
+		for j, m := uint32(0), c.count(list, 4); j < m && c.err == nil; j++ {
+			db.addParent(mimeType, c.str(c.word(list+4+j*4)))
+		}
+	}
+	for _, list := range []uint32{literals, globs} {
+		for i, n := uint32(0), c.count(list, 12); i < n && c.err == nil; i++ {
+			entry := list + 4 + i*12
+			c.addGlob(db, c.str(c.word(entry)), c.word(entry+4), c.word(entry+8))
+		}
+	}
+	c.walkSuffixes(db, c.count(suffixes, nodeSize), c.word(suffixes+4), nil)
+	c.loadMagic(db, magic)
+	return c.err
+}
+
+func (c *cache) addGlob(db *Database, pattern string, mimeType, weightAndFlags uint32) {
+	db.addGlob(glob{
+		pattern:       pattern,
+		mimeType:      c.str(mimeType),
+		weight:        int(weightAndFlags & 0xff),
+		caseSensitive: weightAndFlags&cacheCaseSensitive != 0,
+	})
+}
+
+// walkSuffixes turns the reverse suffix tree into *suffix globs.
+// Leaf nodes have no character, their other fields hold the MIME type and the weight and flags.
+func (c *cache) walkSuffixes(db *Database, n, offset uint32, reversed []rune) {
+	if len(reversed) > 255 {
+		c.err = ErrFormat
+		return
+	}
+	for i := uint32(0); i < n && c.err == nil; i++ {
+		node := offset + i*nodeSize
+		char, a, b := c.word(node), c.word(node+4), c.word(node+8)
+		if char == 0 {
+			suffix := make([]rune, len(reversed))
+			for j, r := range reversed {
+				suffix[len(reversed)-1-j] = r
+			}
+			c.addGlob(db, "*"+string(suffix), a, b)
+			continue
+		}
+		if uint64(a)*nodeSize > uint64(len(c.data)) {
+			c.err = ErrFormat
+			return
+		}
+		c.walkSuffixes(db, a, b, append(reversed, rune(char)))
+	}
+}
+
+func (c *cache) loadMagic(db *Database, list uint32) {
+	n, first := c.count(list, matchSize), c.word(list+8)
+	for i := uint32(0); i < n && c.err == nil; i++ {
+		match := first + i*matchSize
+		db.magic = append(db.magic, &magicRule{
+			priority:  int(c.word(match)),
+			mimeType:  c.str(c.word(match + 4)),
+			matchlets: c.matchlets(c.word(match+8), c.word(match+12), 0),
+		})
+	}
+}
+
+func (c *cache) matchlets(n, offset uint32, depth int) []*matchlet {
+	if depth > 64 || uint64(n)*matchletSize > uint64(len(c.data)) {
+		c.err = ErrFormat
+		return nil
+	}
+	var matchlets []*matchlet
+	for i := uint32(0); i < n && c.err == nil; i++ {
+		entry := offset + i*matchletSize
+		offset, rangeLen := c.word(entry), c.word(entry+4)
+		if offset > maxDetectSize || rangeLen > maxDetectSize {
+			// Such values would not fit an int on 32-bit platforms, and DetectFile does not read that far
+			c.err = ErrFormat
+			return nil
+		}
+		m := &matchlet{offset: int(offset), rangeLen: int(rangeLen)}
+		wordSize, length := int(c.word(entry+8)), c.word(entry+12)
+		m.value = c.bytes(c.word(entry+16), length)
+		if mask := c.word(entry + 20); mask != 0 {
+			m.mask = c.bytes(mask, length)
+		}
+		toNative(m.value, wordSize)
+		toNative(m.mask, wordSize)
+		m.children = c.matchlets(c.word(entry+24), c.word(entry+28), depth+1)
+		matchlets = append(matchlets, m)
+	}
+	return matchlets
+}
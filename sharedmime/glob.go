@@ -0,0 +1,123 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharedmime
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultWeight = 50
+	noGlobs       = "__NOGLOBS__"
+)
+
+type glob struct {
+	pattern       string
+	mimeType      string
+	weight        int
+	caseSensitive bool
+}
+
+// matches reports whether the glob matches name and its lowercase form lower
+func (g *glob) matches(name, lower string) bool {
+	if !g.caseSensitive {
+		name = lower
+	}
+	if !strings.ContainsAny(g.pattern, "*?[") {
+		return g.pattern == name
+	}
+	if g.pattern[0] == '*' && !strings.ContainsAny(g.pattern[1:], "*?[") {
+		return strings.HasSuffix(name, g.pattern[1:])
+	}
+	ok, _ := path.Match(g.pattern, name)
+	return ok
+}
+
+// readGlobs reads a globs2 file with weight:mime/type:glob[:flags] lines
+func (db *Database) readGlobs(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 {
+			continue
+		}
+		weight, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		g := glob{pattern: fields[2], mimeType: fields[1], weight: weight}
+		if len(fields) > 3 {
+			for _, flag := range strings.Split(fields[3], ",") {
+				g.caseSensitive = g.caseSensitive || flag == "cs"
+			}
+		}
+		db.addGlob(g)
+	}
+	return scanner.Err()
+}
+
+// addGlob adds g, or drops the globs of its type read from less important directories for __NOGLOBS__
+func (db *Database) addGlob(g glob) {
+	if g.pattern == noGlobs {
+		kept := db.globs[:0]
+		for _, other := range db.globs {
+			if other.mimeType != g.mimeType {
+				kept = append(kept, other)
+			}
+		}
+		db.globs = kept
+		return
+	}
+	if !g.caseSensitive {
+		g.pattern = strings.ToLower(g.pattern)
+	}
+	db.globs = append(db.globs, g)
+}
+
+// ByName returns the MIME types of the globs matching the base name of name.
+// Case sensitive globs take precedence, so main.C and main.c can have different types.
+// Only the matches with the highest weight and, among those, the longest pattern are kept.
+func (db *Database) ByName(name string) []string {
+	name = filepath.Base(name)
+	best := db.match(name, name, true)
+	if len(best) == 0 {
+		best = db.match(name, strings.ToLower(name), false)
+	}
+	var mimeTypes []string
+	for _, g := range best {
+		mimeTypes = appendMissing(mimeTypes, db.Canonical(g.mimeType))
+	}
+	return mimeTypes
+}
+
+// match returns the best globs of the given case sensitivity matching name and its lowercase form lower
+func (db *Database) match(name, lower string, caseSensitive bool) []*glob {
+	var best []*glob
+	for i := range db.globs {
+		g := &db.globs[i]
+		if g.caseSensitive != caseSensitive || !g.matches(name, lower) {
+			continue
+		}
+		if len(best) > 0 {
+			switch b := best[0]; {
+			case g.weight < b.weight, g.weight == b.weight && len(g.pattern) < len(b.pattern):
+				continue
+			case g.weight > b.weight, len(g.pattern) > len(b.pattern):
+				best = best[:0]
+			}
+		}
+		best = append(best, g)
+	}
+	return best
+}
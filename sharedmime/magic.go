@@ -0,0 +1,278 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharedmime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+)
+
+const (
+	magicHeader = "MIME-Magic\x00\n"
+	noMagic     = "__NOMAGIC__"
+)
+
+// ErrFormat is returned when a database file is malformed
+var ErrFormat = errors.New("sharedmime: malformed database file")
+
+// magicRule is a section of the magic file
+type magicRule struct {
+	priority  int
+	mimeType  string
+	matchlets []*matchlet
+}
+
+// matchlet compares a value with the data at each offset of a range
+type matchlet struct {
+	offset   int
+	rangeLen int
+	value    []byte
+	mask     []byte
+	children []*matchlet
+}
+
+// nativeEndian is the byte order words are stored in by this machine
+var nativeEndian = func() binary.ByteOrder {
+	switch runtime.GOARCH {
+	case "armbe", "arm64be", "m68k", "mips", "mips64", "mips64p32", "ppc", "ppc64", "s390", "s390x", "shbe", "sparc", "sparc64":
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}()
+
+// readMagic reads a magic file made of [priority:mime/type] sections of [indent]>offset=value[&mask][~word-size][+range-length] lines
+func (db *Database) readMagic(r io.Reader) error {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magicHeader))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magicHeader {
+		return ErrFormat
+	}
+	var rule *magicRule
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c == '[' {
+			line, err := br.ReadString('\n')
+			if err != nil || len(line) < 3 || line[len(line)-2] != ']' {
+				return ErrFormat
+			}
+			rule, err = parseSection(line[:len(line)-2])
+			if err != nil {
+				return err
+			}
+			db.magic = append(db.magic, rule)
+			continue
+		}
+		if rule == nil {
+			return ErrFormat
+		}
+		if err = br.UnreadByte(); err != nil {
+			return err
+		}
+		if peek, _ := br.Peek(len(noMagic) + 1); string(peek) == noMagic+"\n" {
+			br.Discard(len(peek)) // nolint: errcheck
+			db.dropMagic(rule.mimeType)
+			continue
+		}
+		indent, m, err := readMatchlet(br)
+		if err != nil {
+			return err
+		}
+		rule.add(indent, m)
+	}
+}
+
+func parseSection(header string) (*magicRule, error) {
+	for i := 0; i < len(header); i++ {
+		if header[i] == ':' {
+			priority, err := strconv.Atoi(header[:i])
+			if err != nil {
+				return nil, ErrFormat
+			}
+			return &magicRule{priority: priority, mimeType: header[i+1:]}, nil
+		}
+	}
+	return nil, ErrFormat
+}
+
+// dropMagic removes the rules for mimeType read from less important directories
+func (db *Database) dropMagic(mimeType string) {
+	current := db.magic[len(db.magic)-1]
+	kept := db.magic[:0]
+	for _, rule := range db.magic[:len(db.magic)-1] {
+		if rule.mimeType != mimeType {
+			kept = append(kept, rule)
+		}
+	}
+	db.magic = append(kept, current)
+}
+
+func readDecimal(br *bufio.Reader, def int) (int, byte, error) {
+	n, digits := 0, 0
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, 0, ErrFormat
+		}
+		if c < '0' || c > '9' {
+			if digits == 0 {
+				n = def
+			}
+			return n, c, nil
+		}
+		n = n*10 + int(c-'0')
+		if n > maxDetectSize {
+			return 0, 0, ErrFormat
+		}
+		digits++
+	}
+}
+
+func readMatchlet(br *bufio.Reader) (int, *matchlet, error) {
+	indent, c, err := readDecimal(br, 0)
+	if err != nil || c != '>' {
+		return 0, nil, ErrFormat
+	}
+	m := &matchlet{rangeLen: 1}
+	if m.offset, c, err = readDecimal(br, -1); err != nil || c != '=' || m.offset < 0 {
+		return 0, nil, ErrFormat
+	}
+	var length [2]byte
+	if _, err = io.ReadFull(br, length[:]); err != nil {
+		return 0, nil, ErrFormat
+	}
+	m.value = make([]byte, int(length[0])<<8|int(length[1]))
+	if _, err = io.ReadFull(br, m.value); err != nil {
+		return 0, nil, ErrFormat
+	}
+	wordSize := 1
+	for {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, ErrFormat
+		}
+		switch c {
+		case '\n':
+			toNative(m.value, wordSize)
+			toNative(m.mask, wordSize)
+			return indent, m, nil
+		case '&':
+			m.mask = make([]byte, len(m.value))
+			if _, err = io.ReadFull(br, m.mask); err != nil {
+				return 0, nil, ErrFormat
+			}
+		case '~':
+			if wordSize, c, err = readDecimal(br, 1); err != nil {
+				return 0, nil, ErrFormat
+			}
+			if err = br.UnreadByte(); err != nil {
+				return 0, nil, err
+			}
+		case '+':
+			if m.rangeLen, c, err = readDecimal(br, 1); err != nil {
+				return 0, nil, ErrFormat
+			}
+			if err = br.UnreadByte(); err != nil {
+				return 0, nil, err
+			}
+		default:
+			return 0, nil, fmt.Errorf("sharedmime: unexpected %q in magic rule", c)
+		}
+	}
+}
+
+// toNative converts the big endian words of the given size in b to the byte order of this machine
+func toNative(b []byte, wordSize int) {
+	switch wordSize {
+	case 2:
+		for i := 0; i+2 <= len(b); i += 2 {
+			nativeEndian.PutUint16(b[i:], binary.BigEndian.Uint16(b[i:]))
+		}
+	case 4:
+		for i := 0; i+4 <= len(b); i += 4 {
+			nativeEndian.PutUint32(b[i:], binary.BigEndian.Uint32(b[i:]))
+		}
+	}
+}
+
+// add appends m below the last matchlet of the previous indent level
+func (r *magicRule) add(indent int, m *matchlet) {
+	list := &r.matchlets
+	for ; indent > 0 && len(*list) > 0; indent-- {
+		list = &(*list)[len(*list)-1].children
+	}
+	*list = append(*list, m)
+}
+
+func (r *magicRule) extent() int {
+	return extent(r.matchlets)
+}
+
+func extent(matchlets []*matchlet) int {
+	max := 0
+	for _, m := range matchlets {
+		if e := m.offset + m.rangeLen - 1 + len(m.value); e > max {
+			max = e
+		}
+		if e := extent(m.children); e > max {
+			max = e
+		}
+	}
+	return max
+}
+
+// matches reports whether any of matchlets, and one of its children when it has any, matches data
+func matches(matchlets []*matchlet, data []byte) bool {
+	for _, m := range matchlets {
+		if m.matches(data) && (len(m.children) == 0 || matches(m.children, data)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *matchlet) matches(data []byte) bool {
+	for offset := m.offset; offset < m.offset+m.rangeLen && offset+len(m.value) <= len(data); offset++ {
+		window := data[offset : offset+len(m.value)]
+		if m.mask == nil {
+			if bytes.Equal(window, m.value) {
+				return true
+			}
+			continue
+		}
+		equal := true
+		for i := range window {
+			if window[i]&m.mask[i] != m.value[i]&m.mask[i] {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return true
+		}
+	}
+	return false
+}
+
+// ByContent returns the MIME type of the magic rule with the highest priority matching data,
+// or an empty string when none does. data needs to hold at most MaxExtent bytes.
+func (db *Database) ByContent(data []byte) string {
+	for _, rule := range db.magic {
+		if matches(rule.matchlets, data) {
+			return db.Canonical(rule.mimeType)
+		}
+	}
+	return ""
+}
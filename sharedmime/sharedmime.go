@@ -0,0 +1,254 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sharedmime detects MIME types by file name and content using the shared MIME-info database.
+//
+// See https://specifications.freedesktop.org/shared-mime-info-spec/latest/ for the database format and the detection rules.
+package sharedmime
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg"
+)
+
+// MIME types used when nothing else matches
+const (
+	OctetStream = "application/octet-stream"
+	PlainText   = "text/plain"
+	Directory   = "inode/directory"
+)
+
+// maxDetectSize is the most DetectFile reads, whatever the extent of a hostile magic rule
+const maxDetectSize = 1 << 20
+
+// Database is the merged content of the mime directories
+type Database struct {
+	globs     []glob
+	magic     []*magicRule
+	aliases   map[string]string
+	parents   map[string][]string
+	maxExtent int
+}
+
+// Dirs returns the mime directories of the data directories, the most important first
+func Dirs() []string {
+	dirs := xdg.SearchDirs(xdg.Data)
+	for i, dir := range dirs {
+		dirs[i] = filepath.Join(dir, "mime")
+	}
+	return dirs
+}
+
+// Load reads the database in Dirs
+func Load() (*Database, error) {
+	return LoadDirs(Dirs())
+}
+
+// LoadDirs reads the database in dirs, the most important first.
+// A directory's mime.cache is used when present, otherwise its globs2, magic, aliases and subclasses files are read.
+// Missing directories and files are skipped.
+func LoadDirs(dirs []string) (*Database, error) {
+	db := &Database{aliases: make(map[string]string), parents: make(map[string][]string)}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		var err error
+		if cache, ok := readCache(filepath.Join(dirs[i], "mime.cache")); ok {
+			err = cache.load(db)
+		} else {
+			err = db.loadFiles(dirs[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(db.magic, func(i, j int) bool {
+		return db.magic[i].priority > db.magic[j].priority
+	})
+	for _, rule := range db.magic {
+		if extent := rule.extent(); extent > db.maxExtent {
+			db.maxExtent = extent
+		}
+	}
+	return db, nil
+}
+
+func (db *Database) loadFiles(dir string) error {
+	loaders := []struct {
+		name string
+		load func(io.Reader) error
+	}{
+		{"globs2", db.readGlobs},
+		{"magic", db.readMagic},
+		{"aliases", db.readPairs(func(alias, mimeType string) { db.aliases[alias] = mimeType })},
+		{"subclasses", db.readPairs(db.addParent)},
+	}
+	for _, loader := range loaders {
+		file, err := os.Open(filepath.Join(dir, loader.name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = loader.load(bufio.NewReader(file))
+		file.Close() // nolint: errcheck
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readPairs returns a reader of files listing two MIME types separated by a space per line
+func (db *Database) readPairs(add func(string, string)) func(io.Reader) error {
+	return func(r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && !strings.HasPrefix(fields[0], "#") {
+				add(fields[0], fields[1])
+			}
+		}
+		return scanner.Err()
+	}
+}
+
+func (db *Database) addParent(mimeType, parent string) {
+	for _, p := range db.parents[mimeType] {
+		if p == parent {
+			return
+		}
+	}
+	db.parents[mimeType] = append(db.parents[mimeType], parent)
+}
+
+// Canonical returns the MIME type mimeType is an alias of, or mimeType itself
+func (db *Database) Canonical(mimeType string) string {
+	if canonical, ok := db.aliases[mimeType]; ok {
+		return canonical
+	}
+	return mimeType
+}
+
+// Parents returns the MIME types mimeType is a direct subclass of.
+// Every text type is a subclass of text/plain and every type but inode types of application/octet-stream.
+func (db *Database) Parents(mimeType string) []string {
+	mimeType = db.Canonical(mimeType)
+	parents := append([]string(nil), db.parents[mimeType]...)
+	if strings.HasPrefix(mimeType, "text/") && mimeType != PlainText {
+		parents = appendMissing(parents, PlainText)
+	}
+	if !strings.HasPrefix(mimeType, "inode/") && mimeType != OctetStream {
+		parents = appendMissing(parents, OctetStream)
+	}
+	return parents
+}
+
+func appendMissing(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+// IsA reports whether mimeType is parent, an alias of it or one of its subclasses
+func (db *Database) IsA(mimeType, parent string) bool {
+	parent = db.Canonical(parent)
+	seen := make(map[string]bool)
+	queue := []string{db.Canonical(mimeType)}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == parent {
+			return true
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		queue = append(queue, db.Parents(current)...)
+	}
+	return false
+}
+
+// MaxExtent returns how many bytes at the start of a file ByContent may look at
+func (db *Database) MaxExtent() int {
+	return db.maxExtent
+}
+
+// Detect returns the MIME type of a file with the given name starting with data.
+// The glob match is used when it is unambiguous. Otherwise the content decides, preferring a glob
+// match that is a subclass of the content match. Files nothing matches are text/plain when data
+// looks like text and application/octet-stream otherwise.
+func (db *Database) Detect(name string, data []byte) string {
+	byName := db.ByName(name)
+	if len(byName) == 1 {
+		return byName[0]
+	}
+	byContent := db.ByContent(data)
+	if byContent == "" {
+		if len(byName) > 0 {
+			return byName[0]
+		}
+		if isText(data) {
+			return PlainText
+		}
+		return OctetStream
+	}
+	for _, mimeType := range byName {
+		if db.IsA(mimeType, byContent) {
+			return mimeType
+		}
+	}
+	return byContent
+}
+
+// DetectFile returns the MIME type of the file at path, reading as much of it as the magic rules need, up to 1 MiB.
+// Directories are inode/directory.
+func (db *Database) DetectFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close() // nolint: errcheck
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return Directory, nil
+	}
+	extent := db.maxExtent
+	if extent < 128 {
+		extent = 128
+	} else if extent > maxDetectSize {
+		extent = maxDetectSize
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(file, int64(extent)))
+	if err != nil {
+		return "", err
+	}
+	return db.Detect(filepath.Base(path), data), nil
+}
+
+// isText reports whether the start of data holds no ASCII control characters but white space and escape
+func isText(data []byte) bool {
+	if len(data) > 128 {
+		data = data[:128]
+	}
+	for _, c := range data {
+		if c < 0x20 && !strings.ContainsRune("\t\n\v\f\r\x1b", rune(c)) || c == 0x7f {
+			return false
+		}
+	}
+	return true
+}
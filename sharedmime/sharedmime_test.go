@@ -0,0 +1,328 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sharedmime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func standupDatabase(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "sharedmime")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "home"))  // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", filepath.Join(tmp, "share")) // nolint: errcheck
+	writeFiles(t, filepath.Join(tmp, "share", "mime"), map[string]string{
+		"globs2": "# comment\n50:text/x-csrc:*.c\n50:text/x-c++src:*.C:cs\n50:image/x-foo:*.foo\n50:text/x-readme:README\n" +
+			"60:application/x-tar-gz:*.tar.gz\n50:application/gzip:*.gz\n40:application/x-backup:*~\n50:application/x-ambiguous:*.amb\n" +
+			"50:text/x-ambiguous:*.amb\n50:application/x-other:*.doc\n50:text/x-doc:*.doc\n50:text/x-alias:*.old\n50:image/x-pattern:img_[0-9].raw\n",
+		"magic": "MIME-Magic\x00\n[60:image/x-foo]\n>0=\x00\x03FOO\n1>3=\x00\x01!\n1>3=\x00\x01?\n" +
+			"[50:application/x-bar]\n>2=\x00\x02BR&\xff\xdf+4\n" +
+			"[45:application/x-office]\n>0=\x00\x04\xd0\xcf\x11\xe0\n" +
+			"[40:application/x-word]\n>0=\x00\x02\x12\x34~2\n" +
+			"[30:application/x-masked]\n>1=\x00\x02AB&\xdf\xdf+2\n",
+		"aliases":    "text/x-alias text/x-csrc\napplication/x-gzip application/gzip\n",
+		"subclasses": "application/x-tar-gz application/gzip\ntext/x-doc application/x-office\n",
+	})
+	writeFiles(t, filepath.Join(tmp, "home", "mime"), map[string]string{
+		"globs2": "50:image/x-foo:__NOGLOBS__\n50:image/x-new:*.foo\n",
+		"magic":  "MIME-Magic\x00\n[60:application/x-bar]\n__NOMAGIC__\n[55:image/x-new]\n>0=\x00\x03NEW\n",
+	})
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestDirs(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupDatabase(t)
+	defer teardown()
+	assert.Equal([]string{filepath.Join(tmp, "home", "mime"), filepath.Join(tmp, "share", "mime")}, Dirs())
+}
+
+func TestByName(t *testing.T) {
+	_, teardown := standupDatabase(t)
+	defer teardown()
+	db, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"main.c", []string{"text/x-csrc"}},
+		{"MAIN.C", []string{"text/x-c++src"}},
+		{"Main.c", []string{"text/x-csrc"}},
+		{"/src/dir/README", []string{"text/x-readme"}},
+		{"readme", []string{"text/x-readme"}},
+		{"archive.tar.gz", []string{"application/x-tar-gz"}},
+		{"archive.gz", []string{"application/gzip"}},
+		{"notes.txt~", []string{"application/x-backup"}},
+		{"picture.foo", []string{"image/x-new"}},
+		{"file.amb", []string{"application/x-ambiguous", "text/x-ambiguous"}},
+		{"legacy.old", []string{"text/x-csrc"}},
+		{"img_4.raw", []string{"image/x-pattern"}},
+		{"img_x.raw", nil},
+		{"unknown", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, db.ByName(test.name))
+		})
+	}
+}
+
+func TestByContent(t *testing.T) {
+	_, teardown := standupDatabase(t)
+	defer teardown()
+	db, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var native [2]byte
+	nativeEndian.PutUint16(native[:], 0x1234)
+	word := string(native[:])
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"Nested", "FOO!", "image/x-foo"},
+		{"NestedSecondChild", "FOO?", "image/x-foo"},
+		{"NestedNoChild", "FOO.", ""},
+		{"Dropped", "xxxxbr", ""},
+		{"Priority", "NEW", "image/x-new"},
+		{"Word", word, "application/x-word"},
+		{"Short", "FO", ""},
+		{"Mask", "zzab", "application/x-masked"},
+		{"Office", "\xd0\xcf\x11\xe0rest", "application/x-office"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, db.ByContent([]byte(test.data)))
+		})
+	}
+	assert.Equal(t, 4, db.MaxExtent())
+}
+
+func TestDetect(t *testing.T) {
+	_, teardown := standupDatabase(t)
+	defer teardown()
+	db, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		expected string
+	}{
+		{"Glob", "main.c", "\x00\x01", "text/x-csrc"},
+		{"Magic", "unknown", "NEW", "image/x-new"},
+		{"AmbiguousGlob", "file.amb", "plain text", "application/x-ambiguous"},
+		{"GlobSubclassOfMagic", "letter.doc", "\xd0\xcf\x11\xe0", "text/x-doc"},
+		{"MagicOverAmbiguousGlob", "file.amb", "NEW", "image/x-new"},
+		{"Text", "unknown", "plain text\n", PlainText},
+		{"Binary", "unknown", "\x00\x01\x02", OctetStream},
+		{"Empty", "unknown", "", PlainText},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, db.Detect(test.file, []byte(test.data)))
+		})
+	}
+}
+
+func TestDetectFile(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupDatabase(t)
+	defer teardown()
+	db, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, filepath.Join(tmp, "files"), map[string]string{"picture": "FOO!"})
+	mimeType, err := db.DetectFile(filepath.Join(tmp, "files", "picture"))
+	assert.NoError(err)
+	assert.Equal("image/x-foo", mimeType)
+	mimeType, err = db.DetectFile(filepath.Join(tmp, "files"))
+	assert.NoError(err)
+	assert.Equal(Directory, mimeType)
+	_, err = db.DetectFile(filepath.Join(tmp, "files", "missing"))
+	assert.True(os.IsNotExist(err))
+
+	// A magic rule looking far into files does not make DetectFile allocate that much
+	db.maxExtent = 1<<31 - 1
+	mimeType, err = db.DetectFile(filepath.Join(tmp, "files", "picture"))
+	assert.NoError(err)
+	assert.Equal("image/x-foo", mimeType)
+}
+
+func TestHierarchy(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupDatabase(t)
+	defer teardown()
+	db, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal("application/gzip", db.Canonical("application/x-gzip"))
+	assert.Equal("image/png", db.Canonical("image/png"))
+	assert.Equal([]string{"application/gzip", OctetStream}, db.Parents("application/x-tar-gz"))
+	assert.Equal([]string{PlainText, OctetStream}, db.Parents("text/x-alias"))
+	assert.Equal([]string{OctetStream}, db.Parents(PlainText))
+	assert.Empty(db.Parents(OctetStream))
+	assert.Empty(db.Parents(Directory))
+	assert.True(db.IsA("application/x-tar-gz", "application/x-gzip"))
+	assert.True(db.IsA("text/x-doc", OctetStream))
+	assert.True(db.IsA("text/x-alias", "text/x-csrc"))
+	assert.False(db.IsA("application/gzip", "application/x-tar-gz"))
+	assert.False(db.IsA(Directory, OctetStream))
+}
+
+func TestMalformedMagic(t *testing.T) {
+	for _, magic := range []string{"NOT-Magic\n", "MIME-Magic\x00\n>0=\x00\x01a\n", "MIME-Magic\x00\n[50:a/b]\n>0=\x00\x05ab\n", "MIME-Magic\x00\n[x:a/b]\n", "MIME-Magic\x00\n[50:a/b]\n>0=\x00\x01a?\n", "MIME-Magic\x00\n[50:a/b]\n>99999999999999999999=\x00\x01a\n"} {
+		tmp, teardown := standupDatabase(t)
+		writeFiles(t, filepath.Join(tmp, "home", "mime"), map[string]string{"magic": magic})
+		_, err := Load()
+		assert.Error(t, err, "%q", magic)
+		teardown()
+	}
+}
+
+// cacheBuilder lays out a mime.cache file for tests
+type cacheBuilder struct {
+	buf []byte
+}
+
+func (b *cacheBuilder) word(values ...uint32) uint32 {
+	offset := uint32(len(b.buf))
+	for _, v := range values {
+		b.buf = append(b.buf, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(b.buf[len(b.buf)-4:], v)
+	}
+	return offset
+}
+
+func (b *cacheBuilder) str(s string) uint32 {
+	offset := uint32(len(b.buf))
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0, 0, 0, 0)[:len(b.buf)+4-len(s)%4]
+	return offset
+}
+
+func (b *cacheBuilder) set(offset, v uint32) {
+	binary.BigEndian.PutUint32(b.buf[offset:], v)
+}
+
+func buildCache() []byte {
+	b := &cacheBuilder{buf: make([]byte, cacheHeaderSize)}
+	binary.BigEndian.PutUint16(b.buf, 1)
+	binary.BigEndian.PutUint16(b.buf[2:], 2)
+	thing, base, alias := b.str("text/x-thing"), b.str("application/x-base"), b.str("text/x-alias")
+	makefile := b.str("text/x-makefile")
+
+	b.set(4, b.word(1, b.str("text/x-alias"), thing))
+	b.set(8, b.word(1, thing, b.word(1, base)))
+	b.set(12, b.word(1, b.str("Makefile"), makefile, cacheCaseSensitive|50))
+
+	// *.thing, reversed as g n i h t .
+	var next uint32
+	for i, c := range "gniht." {
+		node := b.word(uint32(c), 1, 0)
+		if i == 0 {
+			b.set(16, b.word(1, node))
+		} else {
+			b.set(next, node)
+		}
+		next = node + 8
+	}
+	b.set(next, b.word(0, thing, 60))
+	b.set(20, b.word(1, b.str("*.th?"), alias, 40))
+
+	value, child := b.str("BASE"), b.str("!")
+	matchlets := b.word(0, 1, 1, 4, value, 0, 1, 0)
+	b.set(matchlets+28, b.word(4, 1, 1, 1, child, 0, 0, 0))
+	b.set(24, b.word(1, 5, b.word(50, base, 1, matchlets)))
+	return b.buf
+}
+
+func TestCache(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupDatabase(t)
+	defer teardown()
+	writeFiles(t, filepath.Join(tmp, "cache", "mime"), map[string]string{"mime.cache": string(buildCache())})
+
+	db, err := LoadDirs([]string{filepath.Join(tmp, "cache", "mime")})
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal([]string{"text/x-thing"}, db.ByName("My.THING"))
+	assert.Equal([]string{"text/x-makefile"}, db.ByName("Makefile"))
+	assert.Empty(db.ByName("makefile"))
+	assert.Equal([]string{"text/x-thing"}, db.ByName("a.thx"))
+	assert.Equal("application/x-base", db.ByContent([]byte("BASE!")))
+	assert.Equal("", db.ByContent([]byte("BASE?")))
+	assert.True(db.IsA("text/x-thing", "application/x-base"))
+	assert.Equal(5, db.MaxExtent())
+
+	writeFiles(t, filepath.Join(tmp, "cache", "mime"), map[string]string{"mime.cache": string(buildCache()[:60])})
+	_, err = LoadDirs([]string{filepath.Join(tmp, "cache", "mime")})
+	assert.Equal(ErrFormat, err)
+
+	// An offset that would be negative as an int on 32-bit platforms
+	corrupt := buildCache()
+	matchlet := bytes.Index(corrupt, []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 4})
+	if !assert.True(matchlet > 0) {
+		return
+	}
+	binary.BigEndian.PutUint32(corrupt[matchlet:], 0x80000000)
+	writeFiles(t, filepath.Join(tmp, "cache", "mime"), map[string]string{"mime.cache": string(corrupt)})
+	_, err = LoadDirs([]string{filepath.Join(tmp, "cache", "mime")})
+	assert.Equal(ErrFormat, err)
+}
+
+// TestSystemCache checks that the installed mime.cache gives the same answers as the files it was built from
+func TestSystemCache(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "usr", "share", "mime")
+	c, ok := readCache(filepath.Join(dir, "mime.cache"))
+	if !ok {
+		t.Skip("no shared MIME-info database installed")
+	}
+	assert := assert.New(t)
+	fromCache, err := LoadDirs([]string{dir})
+	assert.NoError(err)
+	fromFiles := &Database{aliases: make(map[string]string), parents: make(map[string][]string)}
+	assert.NoError(fromFiles.loadFiles(dir))
+	assert.NoError(c.err)
+	for _, name := range []string{"a.txt", "b.PNG", "c.tar.gz", "Makefile", "d.html", "e.JPG", "f.c", "g.C", "README", "h.odt"} {
+		assert.Equal(fromFiles.ByName(name), fromCache.ByName(name), name)
+	}
+	for _, data := range []string{"\x89PNG\r\n\x1a\n", "%PDF-1.4", "PK\x03\x04", "\x1f\x8b\x08", "<?xml version=\"1.0\"?>"} {
+		assert.Equal(fromFiles.ByContent([]byte(data)), fromCache.ByContent([]byte(data)), "%q", data)
+	}
+	assert.Equal(fromFiles.aliases, fromCache.aliases)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// SocketPathError is returned when no path for a Unix domain socket fits in the platform's limit
+type SocketPathError struct {
+	Path string
+	// Max is the longest path the platform accepts in bytes
+	Max int
+}
+
+func (e *SocketPathError) Error() string {
+	return "xdg: socket path " + e.Path + " is " + strconv.Itoa(len(e.Path)) + " bytes, the limit is " + strconv.Itoa(e.Max)
+}
+
+// socketPathMax returns the longest socket path bind accepts on goos, excluding the terminating NUL of sun_path.
+// Returns 0 where there are no Unix domain sockets.
+func socketPathMax(goos string) int {
+	switch goos {
+	case "darwin", "ios", "freebsd", "openbsd", "netbsd", "dragonfly":
+		return 103
+	case "aix":
+		return 1022
+	case "plan9", "js", "wasip1":
+		return 0
+	}
+	return 107
+}
+
+// SocketPath returns the path of the Unix domain socket called name in RuntimeDir.
+// When that path is too long for the platform, a name hashed from it in a private directory
+// below /tmp is returned instead, which is the same every time. A *SocketPathError is
+// returned when even that does not fit, and ErrUnsupported where there are no Unix domain sockets.
+func (x *XDG) SocketPath(name string) (string, error) {
+	return x.socketPath(name, socketPathMax(runtime.GOOS))
+}
+
+func (x *XDG) socketPath(name string, max int) (string, error) {
+	if max == 0 {
+		return "", ErrUnsupported
+	}
+	dir, err := x.RuntimeDir()
+	if err != nil {
+		return "", err
+	}
+	if !plainName(name) {
+		return "", &UnsafeNameError{Name: name, Dir: dir}
+	}
+	path := filepath.Join(dir, name)
+	if len(path) <= max {
+		return path, nil
+	}
+	tmp := string(filepath.Separator) + "tmp"
+	if runtime.GOOS == "windows" {
+		tmp = os.TempDir()
+	}
+	short, err := privateTempDir(tmp)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(path))
+	hashed := filepath.Join(short, hex.EncodeToString(sum[:8])+".sock")
+	if len(hashed) > max {
+		return "", &SocketPathError{Path: path, Max: max}
+	}
+	return hashed, nil
+}
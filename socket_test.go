@@ -0,0 +1,94 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSocketPathMax(t *testing.T) {
+	tests := []struct {
+		goos string
+		max  int
+	}{
+		{"linux", 107},
+		{"android", 107},
+		{"windows", 107},
+		{"solaris", 107},
+		{"darwin", 103},
+		{"freebsd", 103},
+		{"aix", 1022},
+		{"plan9", 0},
+		{"js", 0},
+	}
+	for _, test := range tests {
+		t.Run(test.goos, func(t *testing.T) {
+			assert.Equal(t, test.max, socketPathMax(test.goos))
+		})
+	}
+}
+
+func TestXDG_SocketPath(t *testing.T) {
+	if socketPathMax(runtime.GOOS) == 0 {
+		t.Skip("no Unix domain sockets on", runtime.GOOS)
+	}
+	assert := assert.New(t)
+	tmp, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	path, err := x.SocketPath("control.sock")
+	assert.NoError(err)
+	assert.Equal(filepath.Join(tmp, "run", "OpenPeeDeeP", "XDG", "control.sock"), path)
+
+	_, err = x.SocketPath("../control.sock")
+	assert.IsType(&UnsafeNameError{}, err)
+
+	long := filepath.Join(tmp, "run", strings.Repeat("deeply-nested-", 8))
+	os.Setenv("XDG_RUNTIME_DIR", long) // nolint: errcheck
+	assert.NoError(os.MkdirAll(long, 0700))
+	path, err = x.SocketPath("control.sock")
+	if !assert.NoError(err) {
+		return
+	}
+	assert.True(len(path) <= socketPathMax(runtime.GOOS))
+	assert.Equal(".sock", filepath.Ext(path))
+	assert.NotContains(path, "deeply-nested")
+	again, err := x.SocketPath("control.sock")
+	assert.NoError(err)
+	assert.Equal(path, again)
+	other, err := x.SocketPath("other.sock")
+	assert.NoError(err)
+	assert.NotEqual(path, other)
+
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		l, err := net.Listen("unix", path)
+		if assert.NoError(err) {
+			l.Close() // nolint: errcheck
+		}
+	}
+}
+
+func TestXDG_SocketPathTooLong(t *testing.T) {
+	assert := assert.New(t)
+	_, teardown := standupRuntime(t)
+	defer teardown()
+	x := New("OpenPeeDeeP", "XDG")
+
+	_, err := x.socketPath("control.sock", 20)
+	if assert.IsType(&SocketPathError{}, err) {
+		assert.Equal(20, err.(*SocketPathError).Max)
+		assert.Contains(err.Error(), "the limit is 20")
+	}
+	_, err = x.socketPath("control.sock", 0)
+	assert.Equal(ErrUnsupported, err)
+}
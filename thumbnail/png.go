@@ -0,0 +1,93 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+const pngSignature = "\x89PNG\r\n\x1a\n"
+
+// ErrFormat is returned when a thumbnail is not a well formed PNG image
+var ErrFormat = errors.New("thumbnail: not a valid PNG image")
+
+// ReadText returns the keywords and text of the tEXt chunks in the PNG image read from r.
+// Every chunk up to IEND is read and its checksum verified.
+func ReadText(r io.Reader) (map[string]string, error) {
+	signature := make([]byte, len(pngSignature))
+	if _, err := io.ReadFull(r, signature); err != nil || string(signature) != pngSignature {
+		return nil, ErrFormat
+	}
+	text := make(map[string]string)
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, ErrFormat
+		}
+		length := binary.BigEndian.Uint32(header[:4])
+		typ := string(header[4:])
+		if length > 1<<24 {
+			return nil, ErrFormat
+		}
+		data := make([]byte, length+4)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, ErrFormat
+		}
+		crc := crc32.NewIEEE()
+		crc.Write(header[4:])    // nolint: errcheck
+		crc.Write(data[:length]) // nolint: errcheck
+		if crc.Sum32() != binary.BigEndian.Uint32(data[length:]) {
+			return nil, ErrFormat
+		}
+		if typ == "IEND" {
+			return text, nil
+		}
+		if typ != "tEXt" {
+			continue
+		}
+		if i := bytes.IndexByte(data[:length], 0); i > 0 {
+			text[string(data[:i])] = latin1(data[i+1 : length])
+		}
+	}
+}
+
+// latin1 converts the ISO 8859-1 text of a tEXt chunk to UTF-8
+func latin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// insertText adds a tEXt chunk for every keyword and text pair right after the IHDR chunk of the encoded PNG image.
+// The thumbnail keys only hold URIs and numbers, which are ASCII.
+func insertText(img []byte, text [][2]string) ([]byte, error) {
+	const ihdrEnd = len(pngSignature) + 4 + 4 + 13 + 4
+	if len(img) < ihdrEnd || string(img[:len(pngSignature)]) != pngSignature || string(img[12:16]) != "IHDR" {
+		return nil, ErrFormat
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, len(img)+256))
+	buf.Write(img[:ihdrEnd])
+	for _, kv := range text {
+		data := []byte(kv[0] + "\x00" + kv[1])
+		var word [4]byte
+		binary.BigEndian.PutUint32(word[:], uint32(len(data)))
+		buf.Write(word[:])
+		crc := crc32.NewIEEE()
+		crc.Write([]byte("tEXt")) // nolint: errcheck
+		crc.Write(data)           // nolint: errcheck
+		buf.WriteString("tEXt")
+		buf.Write(data)
+		binary.BigEndian.PutUint32(word[:], crc.Sum32())
+		buf.Write(word[:])
+	}
+	buf.Write(img[ihdrEnd:])
+	return buf.Bytes(), nil
+}
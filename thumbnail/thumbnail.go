@@ -0,0 +1,224 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package thumbnail looks up and stores thumbnails in the shared thumbnail cache.
+//
+// See https://specifications.freedesktop.org/thumbnail-spec/latest/ for the layout of the cache.
+package thumbnail
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/OpenPeeDeeP/xdg"
+	"github.com/OpenPeeDeeP/xdg/internal/atomicfile"
+)
+
+// Size is the size class of a thumbnail
+type Size int
+
+// The size classes in the order of their size
+const (
+	Normal Size = iota
+	Large
+	XLarge
+	XXLarge
+)
+
+var sizes = [...]struct {
+	dir    string
+	pixels int
+}{
+	Normal:  {"normal", 128},
+	Large:   {"large", 256},
+	XLarge:  {"x-large", 512},
+	XXLarge: {"xx-large", 1024},
+}
+
+// String returns the name of the directory holding thumbnails of this size
+func (s Size) String() string {
+	if s < Normal || s > XXLarge {
+		return "Size(" + strconv.Itoa(int(s)) + ")"
+	}
+	return sizes[s].dir
+}
+
+// Pixels returns the maximum width and height of thumbnails of this size
+func (s Size) Pixels() int {
+	if s < Normal || s > XXLarge {
+		return 0
+	}
+	return sizes[s].pixels
+}
+
+// The text keys stored in a thumbnail
+const (
+	KeyURI   = "Thumb::URI"
+	KeyMTime = "Thumb::MTime"
+	KeySize  = "Thumb::Size"
+)
+
+// Dir returns the root of the thumbnail cache
+func Dir() string {
+	return filepath.Join(xdg.CacheHome(), "thumbnails")
+}
+
+// URI returns the canonical file URI of path thumbnails are keyed by
+func URI(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return "file://" + escape(path), nil
+}
+
+// escape percent encodes everything but the unreserved and sub-delimiter characters, like GLib does
+func escape(path string) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte("-._~!$&'()*+,;=:@/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&0xf])
+	}
+	return b.String()
+}
+
+// Name returns the file name of the thumbnail for uri
+func Name(uri string) string {
+	sum := md5.Sum([]byte(uri))
+	return hex.EncodeToString(sum[:]) + ".png"
+}
+
+// Path returns where the thumbnail of the given size for uri is stored
+func Path(uri string, size Size) string {
+	return filepath.Join(Dir(), size.String(), Name(uri))
+}
+
+// FailPath returns where app records that it could not create a thumbnail for uri.
+// app should hold the name and version of the application, such as viewer-1.2.
+func FailPath(uri, app string) string {
+	return filepath.Join(Dir(), "fail", app, Name(uri))
+}
+
+// Lookup returns the path of an up to date thumbnail for the file at path.
+// Thumbnails of the given size are preferred, larger ones are used when it is missing or outdated.
+// Returns an empty string when there is no valid thumbnail.
+func Lookup(path string, size Size) (string, error) {
+	uri, info, err := source(path)
+	if err != nil {
+		return "", err
+	}
+	for ; size <= XXLarge; size++ {
+		thumb := Path(uri, size)
+		if valid(thumb, uri, info) {
+			return thumb, nil
+		}
+	}
+	return "", nil
+}
+
+// Failed reports whether app recorded that it could not create a thumbnail for the file at path,
+// and the file did not change since
+func Failed(path, app string) (bool, error) {
+	uri, info, err := source(path)
+	if err != nil {
+		return false, err
+	}
+	return valid(FailPath(uri, app), uri, info), nil
+}
+
+func source(path string) (string, os.FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	uri, err := URI(path)
+	return uri, info, err
+}
+
+// valid reports whether the thumbnail at thumb was created for uri and the current version of the file
+func valid(thumb, uri string, info os.FileInfo) bool {
+	file, err := os.Open(thumb)
+	if err != nil {
+		return false
+	}
+	defer file.Close() // nolint: errcheck
+	text, err := ReadText(file)
+	if err != nil {
+		return false
+	}
+	return text[KeyURI] == uri && text[KeyMTime] == mtime(info)
+}
+
+func mtime(info os.FileInfo) string {
+	return strconv.FormatInt(info.ModTime().Unix(), 10)
+}
+
+// Save stores img as the thumbnail of the given size for the file at path and returns where it was written.
+// img should already be scaled to fit in size.Pixels.
+func Save(path string, size Size, img image.Image) (string, error) {
+	if size.Pixels() == 0 {
+		return "", fmt.Errorf("thumbnail: invalid size %v", size)
+	}
+	if b := img.Bounds(); b.Dx() > size.Pixels() || b.Dy() > size.Pixels() {
+		return "", fmt.Errorf("thumbnail: %dx%d image is larger than %s thumbnails", b.Dx(), b.Dy(), size)
+	}
+	uri, info, err := source(path)
+	if err != nil {
+		return "", err
+	}
+	thumb := Path(uri, size)
+	return thumb, write(thumb, img, uri, info)
+}
+
+// SaveFailed records that app could not create a thumbnail for the file at path
+func SaveFailed(path, app string) error {
+	if app == "" || strings.ContainsAny(app, `/\`) {
+		return fmt.Errorf("thumbnail: invalid application name %q", app)
+	}
+	uri, info, err := source(path)
+	if err != nil {
+		return err
+	}
+	return write(FailPath(uri, app), image.NewNRGBA(image.Rect(0, 0, 1, 1)), uri, info)
+}
+
+// write encodes img with the text chunks identifying the file and atomically replaces thumb
+func write(thumb string, img image.Image, uri string, info os.FileInfo) error {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return err
+	}
+	data, err := insertText(buf.Bytes(), [][2]string{
+		{KeyURI, uri},
+		{KeyMTime, mtime(info)},
+		{KeySize, strconv.FormatInt(info.Size(), 10)},
+	})
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(thumb)
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(thumb, data, 0600)
+}
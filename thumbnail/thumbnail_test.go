@@ -0,0 +1,160 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupCache(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "thumbnail")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(tmp, "cache")) // nolint: errcheck
+	path := filepath.Join(tmp, "photo.jpg")
+	if err = ioutil.WriteFile(path, []byte("not really a jpeg"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func TestURI(t *testing.T) {
+	if filepath.Separator != '/' {
+		t.Skip("paths are not slash separated")
+	}
+	tests := []struct {
+		path string
+		uri  string
+		name string
+	}{
+		{"/home/jens/photos/me.png", "file:///home/jens/photos/me.png", "c6ee772d9e49320e97ec29a7eb5b1697.png"},
+		{"/tmp/My Photos/100%.png", "file:///tmp/My%20Photos/100%25.png", ""},
+		{"/tmp/it's (1)!.png", "file:///tmp/it's%20(1)!.png", ""},
+		{"/tmp/café#1?.png", "file:///tmp/caf%C3%A9%231%3F.png", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			assert := assert.New(t)
+			uri, err := URI(test.path)
+			assert.NoError(err)
+			assert.Equal(test.uri, uri)
+			if test.name != "" {
+				assert.Equal(test.name, Name(uri))
+			}
+		})
+	}
+}
+
+func TestSize(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("x-large", XLarge.String())
+	assert.Equal(1024, XXLarge.Pixels())
+	assert.Equal("Size(7)", Size(7).String())
+	assert.Equal(0, Size(-1).Pixels())
+}
+
+func TestSaveAndLookup(t *testing.T) {
+	assert := assert.New(t)
+	path, teardown := standupCache(t)
+	defer teardown()
+
+	thumb, err := Lookup(path, Normal)
+	assert.NoError(err)
+	assert.Equal("", thumb)
+
+	thumb, err = Save(path, Large, image.NewNRGBA(image.Rect(0, 0, 256, 192)))
+	if !assert.NoError(err) {
+		return
+	}
+	uri, _ := URI(path)
+	assert.Equal(filepath.Join(Dir(), "large", Name(uri)), thumb)
+	info, err := os.Stat(thumb)
+	if assert.NoError(err) {
+		assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	}
+
+	found, err := Lookup(path, Normal)
+	assert.NoError(err)
+	assert.Equal(thumb, found)
+	found, err = Lookup(path, XLarge)
+	assert.NoError(err)
+	assert.Equal("", found)
+
+	file, err := os.Open(thumb)
+	if assert.NoError(err) {
+		defer file.Close() // nolint: errcheck
+		img, err := png.Decode(file)
+		assert.NoError(err)
+		assert.Equal(image.Rect(0, 0, 256, 192), img.Bounds())
+	}
+
+	later := time.Now().Add(time.Hour)
+	assert.NoError(os.Chtimes(path, later, later))
+	found, err = Lookup(path, Normal)
+	assert.NoError(err)
+	assert.Equal("", found)
+}
+
+func TestSaveTooLarge(t *testing.T) {
+	assert := assert.New(t)
+	path, teardown := standupCache(t)
+	defer teardown()
+	_, err := Save(path, Normal, image.NewNRGBA(image.Rect(0, 0, 256, 256)))
+	assert.Error(err)
+	_, err = Save(path, Size(9), image.NewNRGBA(image.Rect(0, 0, 1, 1)))
+	assert.Error(err)
+}
+
+func TestFailed(t *testing.T) {
+	assert := assert.New(t)
+	path, teardown := standupCache(t)
+	defer teardown()
+
+	failed, err := Failed(path, "viewer-1.0")
+	assert.NoError(err)
+	assert.False(failed)
+	assert.NoError(SaveFailed(path, "viewer-1.0"))
+	failed, err = Failed(path, "viewer-1.0")
+	assert.NoError(err)
+	assert.True(failed)
+	failed, err = Failed(path, "other-2.0")
+	assert.NoError(err)
+	assert.False(failed)
+	assert.Error(SaveFailed(path, "../viewer"))
+}
+
+func TestReadText(t *testing.T) {
+	assert := assert.New(t)
+	buf := new(bytes.Buffer)
+	assert.NoError(png.Encode(buf, image.NewGray(image.Rect(0, 0, 2, 2))))
+	data, err := insertText(buf.Bytes(), [][2]string{{KeyURI, "file:///a"}, {"Comment", "caf\xe9"}})
+	if !assert.NoError(err) {
+		return
+	}
+	text, err := ReadText(bytes.NewReader(data))
+	assert.NoError(err)
+	assert.Equal(map[string]string{KeyURI: "file:///a", "Comment": "café"}, text)
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[45]++
+	_, err = ReadText(bytes.NewReader(corrupt))
+	assert.Equal(ErrFormat, err)
+	_, err = ReadText(bytes.NewReader(data[:60]))
+	assert.Equal(ErrFormat, err)
+	_, err = ReadText(bytes.NewReader([]byte("GIF89a")))
+	assert.Equal(ErrFormat, err)
+}
@@ -0,0 +1,19 @@
+// +build windows plan9
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trash
+
+import "path/filepath"
+
+// device treats every path as being on the same device, so the home trash is always used
+func device(path string) (uint64, error) {
+	return 0, nil
+}
+
+// topDir returns the volume holding path
+func topDir(path string) (string, error) {
+	return filepath.VolumeName(path) + string(filepath.Separator), nil
+}
@@ -0,0 +1,44 @@
+// +build !windows,!plan9
+
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trash
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// device returns the ID of the device holding path
+func device(path string) (uint64, error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Dev), nil // nolint: unconvert
+}
+
+// topDir returns the top directory of the mount holding path
+func topDir(path string) (string, error) {
+	dir := filepath.Dir(path)
+	dev, err := device(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+		parentDev, err := device(parent)
+		if err != nil {
+			return "", err
+		}
+		if parentDev != dev {
+			return dir, nil
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package trash moves files to and from the trash.
+//
+// See https://specifications.freedesktop.org/trash-spec/latest/ for the layout of the trash directories.
+package trash
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/OpenPeeDeeP/xdg"
+	"github.com/OpenPeeDeeP/xdg/desktopentry"
+)
+
+const (
+	infoGroup  = "Trash Info"
+	infoSuffix = ".trashinfo"
+	dateFormat = "2006-01-02T15:04:05"
+)
+
+// ErrNoTrash is returned when no trash directory can be used for a file
+var ErrNoTrash = errors.New("trash: no usable trash directory")
+
+// Trash is a trash directory holding the files and info directories
+type Trash struct {
+	Dir string
+	// Top is the top directory of the mount a per mount trash belongs to.
+	// Original paths are stored relative to it. It is empty for the home trash.
+	Top string
+}
+
+// Item is a file or directory in the trash
+type Item struct {
+	// Name is the name of the item in the files directory of the trash
+	Name string
+	// Path is the absolute path the item was deleted from
+	Path         string
+	DeletionDate time.Time
+
+	trash *Trash
+}
+
+// Home returns the trash in DataHome used for files on the same mount as the home directory
+func Home() *Trash {
+	return &Trash{Dir: filepath.Join(xdg.DataHome(), "Trash")}
+}
+
+// ForTop returns the trash of the mount with the given top directory.
+// $topdir/.Trash/$uid is used when the administrator provided $topdir/.Trash with the sticky bit set,
+// otherwise $topdir/.Trash-$uid is used. The user's directory is created when it is missing.
+func ForTop(top string) (*Trash, error) {
+	uid := strconv.Itoa(os.Getuid())
+	shared := filepath.Join(top, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		t := &Trash{Dir: filepath.Join(shared, uid), Top: top}
+		if err := t.create(); err == nil {
+			return t, nil
+		}
+	}
+	t := &Trash{Dir: filepath.Join(top, ".Trash-"+uid), Top: top}
+	if info, err := os.Lstat(t.Dir); err == nil && (!info.IsDir() || info.Mode()&os.ModeSymlink != 0) {
+		return nil, ErrNoTrash
+	}
+	if err := t.create(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// For returns the trash path should be moved to. That is the home trash when path is on the
+// same mount as DataHome, otherwise it is the trash at the top directory of the mount holding path.
+func For(path string) (*Trash, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	home := Home()
+	homeDev, err := device(existing(home.Dir))
+	if err != nil {
+		return nil, err
+	}
+	dev, err := device(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	if dev == homeDev {
+		return home, nil
+	}
+	top, err := topDir(path)
+	if err != nil {
+		return nil, err
+	}
+	return ForTop(top)
+}
+
+// existing returns path or its closest parent that exists
+func existing(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// Put moves path to the trash returned by For
+func Put(path string) (*Item, error) {
+	t, err := For(path)
+	if err != nil {
+		return nil, err
+	}
+	return t.Put(path)
+}
+
+func (t *Trash) filesDir() string {
+	return filepath.Join(t.Dir, "files")
+}
+
+func (t *Trash) infoDir() string {
+	return filepath.Join(t.Dir, "info")
+}
+
+func (t *Trash) create() error {
+	for _, dir := range []string{t.filesDir(), t.infoDir()} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put moves path to the trash.
+// The info file is written first, so the name in the trash is reserved before the file is moved.
+func (t *Trash) Put(path string) (*Item, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = os.Lstat(path); err != nil {
+		return nil, err
+	}
+	if err = t.create(); err != nil {
+		return nil, err
+	}
+	stored := path
+	if t.Top != "" {
+		if rel, err := filepath.Rel(t.Top, path); err == nil && !strings.HasPrefix(rel, "..") {
+			stored = rel
+		}
+	}
+	item := &Item{Path: path, DeletionDate: time.Now().Truncate(time.Second), trash: t}
+	info := new(desktopentry.File)
+	g := info.AddGroup(infoGroup)
+	g.SetRaw("Path", (&url.URL{Path: filepath.ToSlash(stored)}).EscapedPath())
+	g.SetRaw("DeletionDate", item.DeletionDate.Format(dateFormat))
+
+	base := filepath.Base(path)
+	for n := 1; ; n++ {
+		item.Name = base
+		if n > 1 {
+			item.Name = base + "." + strconv.Itoa(n)
+		}
+		file, err := os.OpenFile(item.infoPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		_, err = info.WriteTo(file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			if _, statErr := os.Lstat(item.filePath()); statErr == nil {
+				os.Remove(item.infoPath()) // nolint: errcheck
+				continue
+			}
+			err = os.Rename(path, item.filePath())
+		}
+		if err != nil {
+			os.Remove(item.infoPath()) // nolint: errcheck
+			return nil, err
+		}
+		return item, nil
+	}
+}
+
+// Items returns the items in the trash, the most recently deleted first.
+// Info files without a matching file and files that can not be parsed are left out.
+func (t *Trash) Items() ([]*Item, error) {
+	infos, err := ioutil.ReadDir(t.infoDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var items []*Item
+	for _, info := range infos {
+		if info.IsDir() || !strings.HasSuffix(info.Name(), infoSuffix) {
+			continue
+		}
+		item, err := t.item(strings.TrimSuffix(info.Name(), infoSuffix))
+		if err != nil {
+			continue
+		}
+		if _, err := os.Lstat(item.filePath()); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletionDate.After(items[j].DeletionDate)
+	})
+	return items, nil
+}
+
+func (t *Trash) item(name string) (*Item, error) {
+	item := &Item{Name: name, trash: t}
+	f, err := desktopentry.ParseFile(item.infoPath())
+	if err != nil {
+		return nil, err
+	}
+	g := f.Group(infoGroup)
+	if g == nil {
+		return nil, fmt.Errorf("trash: %s has no [%s] group", item.infoPath(), infoGroup)
+	}
+	raw, _ := g.Raw("Path")
+	path, err := url.PathUnescape(raw)
+	if err != nil || path == "" {
+		return nil, fmt.Errorf("trash: invalid Path in %s", item.infoPath())
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.Top, path)
+	}
+	item.Path = path
+	date, _ := g.Raw("DeletionDate")
+	item.DeletionDate, _ = time.ParseInLocation(dateFormat, date, time.Local)
+	return item, nil
+}
+
+// Empty permanently removes every item in the trash
+func (t *Trash) Empty() error {
+	for _, dir := range []string{t.filesDir(), t.infoDir()} {
+		entries, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (i *Item) filePath() string {
+	return filepath.Join(i.trash.filesDir(), i.Name)
+}
+
+func (i *Item) infoPath() string {
+	return filepath.Join(i.trash.infoDir(), i.Name+infoSuffix)
+}
+
+// Trash returns the trash holding the item
+func (i *Item) Trash() *Trash {
+	return i.trash
+}
+
+// Restore moves the item back to where it was deleted from.
+// An error satisfying os.IsExist is returned when something else is already there.
+func (i *Item) Restore() error {
+	if _, err := os.Lstat(i.Path); err == nil {
+		return &os.PathError{Op: "restore", Path: i.Path, Err: os.ErrExist}
+	}
+	if err := os.MkdirAll(filepath.Dir(i.Path), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(i.filePath(), i.Path); err != nil {
+		return err
+	}
+	return os.Remove(i.infoPath())
+}
+
+// Delete permanently removes the item from the trash
+func (i *Item) Delete() error {
+	if err := os.RemoveAll(i.filePath()); err != nil {
+		return err
+	}
+	return os.Remove(i.infoPath())
+}
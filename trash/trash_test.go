@@ -0,0 +1,217 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package trash
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func standupTrash(t *testing.T) (string, func()) {
+	tmp, err := ioutil.TempDir("", "trash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmp, err = filepath.EvalSymlinks(tmp); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data")) // nolint: errcheck
+	return tmp, func() {
+		os.RemoveAll(tmp) // nolint: errcheck
+	}
+}
+
+func writeFile(t *testing.T, path string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(path), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPutAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupTrash(t)
+	defer teardown()
+	path := filepath.Join(tmp, "docs", "100% done.txt")
+	writeFile(t, path)
+
+	item, err := Put(path)
+	if !assert.NoError(err) {
+		return
+	}
+	assert.Equal(Home(), item.Trash())
+	assert.Equal("100% done.txt", item.Name)
+	_, err = os.Stat(path)
+	assert.True(os.IsNotExist(err))
+	info, err := ioutil.ReadFile(filepath.Join(tmp, "data", "Trash", "info", "100% done.txt.trashinfo"))
+	assert.NoError(err)
+	assert.Equal("[Trash Info]\nPath="+filepath.ToSlash(filepath.Join(tmp, "docs"))+"/100%25%20done.txt\nDeletionDate="+item.DeletionDate.Format(dateFormat)+"\n", string(info))
+
+	items, err := Home().Items()
+	assert.NoError(err)
+	if assert.Len(items, 1) {
+		assert.Equal(path, items[0].Path)
+		assert.True(item.DeletionDate.Equal(items[0].DeletionDate))
+		assert.NoError(items[0].Restore())
+	}
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(path, string(content))
+	items, err = Home().Items()
+	assert.NoError(err)
+	assert.Empty(items)
+}
+
+func TestPutNameCollision(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupTrash(t)
+	defer teardown()
+	var names []string
+	for _, dir := range []string{"a", "b", "c"} {
+		path := filepath.Join(tmp, dir, "notes.txt")
+		writeFile(t, path)
+		item, err := Home().Put(path)
+		if !assert.NoError(err) {
+			return
+		}
+		names = append(names, item.Name)
+	}
+	assert.Equal([]string{"notes.txt", "notes.txt.2", "notes.txt.3"}, names)
+
+	items, err := Home().Items()
+	assert.NoError(err)
+	assert.Len(items, 3)
+}
+
+func TestRestoreExisting(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupTrash(t)
+	defer teardown()
+	path := filepath.Join(tmp, "notes.txt")
+	writeFile(t, path)
+	item, err := Home().Put(path)
+	if !assert.NoError(err) {
+		return
+	}
+	writeFile(t, path)
+	assert.True(os.IsExist(item.Restore()))
+}
+
+func TestItemsSkipsOrphans(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupTrash(t)
+	defer teardown()
+	writeFile(t, filepath.Join(tmp, "data", "Trash", "info", "broken.trashinfo"))
+	writeFile(t, filepath.Join(tmp, "data", "Trash", "files", "broken"))
+	gone := []byte("[Trash Info]\nPath=/gone\nDeletionDate=2017-01-02T03:04:05\n")
+	if err := ioutil.WriteFile(filepath.Join(tmp, "data", "Trash", "info", "gone.trashinfo"), gone, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := Home().Items()
+	assert.NoError(err)
+	assert.Empty(items)
+}
+
+func TestEmptyAndDelete(t *testing.T) {
+	assert := assert.New(t)
+	tmp, teardown := standupTrash(t)
+	defer teardown()
+	for _, name := range []string{"one", "two", "three"} {
+		path := filepath.Join(tmp, name, "file")
+		writeFile(t, path)
+		if _, err := Home().Put(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	items, err := Home().Items()
+	assert.NoError(err)
+	if assert.Len(items, 3) {
+		assert.NoError(items[0].Delete())
+	}
+	items, err = Home().Items()
+	assert.NoError(err)
+	assert.Len(items, 2)
+
+	assert.NoError(Home().Empty())
+	items, err = Home().Items()
+	assert.NoError(err)
+	assert.Empty(items)
+	files, err := ioutil.ReadDir(filepath.Join(tmp, "data", "Trash", "files"))
+	assert.NoError(err)
+	assert.Empty(files)
+}
+
+func TestForTop(t *testing.T) {
+	uid := strconv.Itoa(os.Getuid())
+	tests := []struct {
+		name   string
+		shared os.FileMode
+		dir    string
+	}{
+		{"UserTrash", 0, ".Trash-" + uid},
+		{"SharedTrash", os.ModeSticky | 0777, filepath.Join(".Trash", uid)},
+		{"SharedTrashNotSticky", 0777, ".Trash-" + uid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := assert.New(t)
+			top, teardown := standupTrash(t)
+			defer teardown()
+			if test.shared != 0 {
+				if err := os.Mkdir(filepath.Join(top, ".Trash"), 0777); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(filepath.Join(top, ".Trash"), test.shared); err != nil {
+					t.Fatal(err)
+				}
+			}
+			trash, err := ForTop(top)
+			if !assert.NoError(err) {
+				return
+			}
+			assert.Equal(filepath.Join(top, test.dir), trash.Dir)
+
+			path := filepath.Join(top, "media", "song.ogg")
+			writeFile(t, path)
+			item, err := trash.Put(path)
+			if !assert.NoError(err) {
+				return
+			}
+			info, err := ioutil.ReadFile(filepath.Join(trash.Dir, "info", "song.ogg.trashinfo"))
+			assert.NoError(err)
+			assert.Contains(string(info), "\nPath=media/song.ogg\n")
+			items, err := trash.Items()
+			assert.NoError(err)
+			if assert.Len(items, 1) {
+				assert.Equal(path, items[0].Path)
+			}
+			assert.NoError(item.Restore())
+			_, err = os.Stat(path)
+			assert.NoError(err)
+		})
+	}
+}
+
+func TestForTopSymlink(t *testing.T) {
+	assert := assert.New(t)
+	top, teardown := standupTrash(t)
+	defer teardown()
+	if err := os.Mkdir(filepath.Join(top, "elsewhere"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(top, "elsewhere"), filepath.Join(top, ".Trash-"+strconv.Itoa(os.Getuid()))); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+	_, err := ForTop(top)
+	assert.Equal(ErrNoTrash, err)
+}
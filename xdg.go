@@ -6,11 +6,17 @@
 package xdg
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 )
 
+// ErrUnsupported is returned when neither the environment nor the platform provide a directory
+var ErrUnsupported = errors.New("xdg: no directory is available on this platform")
+
 var defaulter xdgDefaulter = new(osDefaulter)
 
 type xdgDefaulter interface {
@@ -19,6 +25,8 @@ type xdgDefaulter interface {
 	defaultConfigHome() string
 	defaultConfigDirs() []string
 	defaultCacheHome() string
+	defaultStateHome() string
+	defaultBinHome() string
 }
 
 type osDefaulter struct {
@@ -30,10 +38,70 @@ func setDefaulter(def xdgDefaulter) {
 	defaulter = def
 }
 
+// Kind identifies a category of base directory
+type Kind int
+
+// The kinds of base directories
+const (
+	Data Kind = iota
+	Config
+	Cache
+	State
+	Runtime
+)
+
+var kindNames = []string{"data", "config", "cache", "state", "runtime"}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return kindNames[k]
+}
+
 // XDG is information about the currently running application
 type XDG struct {
 	Vendor      string
 	Application string
+
+	// EnvPrefix lets the environment relocate this application's home directories.
+	// When set, PREFIX_DATA_HOME, PREFIX_CONFIG_HOME, PREFIX_CACHE_HOME, PREFIX_STATE_HOME
+	// and PREFIX_RUNTIME_DIR take precedence over the derived Vendor/Application paths.
+	EnvPrefix string
+
+	// Portable keeps every home directory next to the running executable,
+	// such as <exe dir>/config. Portable mode is also turned on when a file
+	// named PortableMarker exists beside the executable or when the environment
+	// variable named PortableEnv is not empty.
+	Portable       bool
+	PortableMarker string
+	PortableEnv    string
+
+	// Naming turns Vendor and Application into the path appended to each base directory.
+	// VendorAppNaming is used when it is nil.
+	Naming NamingPolicy
+
+	// System keeps the runtime files of a service running for the whole system, such as PID files and sockets,
+	// in /run (/var/run on other Unix systems) instead of the user's runtime directory.
+	System bool
+
+	// LogRotation controls when the files written by LogWriter are rotated and how many are kept
+	LogRotation LogRotation
+
+	// PluginManifest is the file DiscoverPlugins requires in a plugin directory.
+	// DefaultPluginManifest is used when it is empty.
+	PluginManifest string
+
+	// SafeNames makes the Query methods reject file names that escape the directory being searched,
+	// either lexically (absolute paths or ..) or through symbolic links.
+	SafeNames bool
+
+	// SecureConfig makes the Query methods skip config files that fail the checks of Audit, the way ssh ignores
+	// config files other users can change. A file in a less important directory is used instead.
+	SecureConfig bool
+
+	// kinds are the categories added with RegisterKind
+	kinds map[string]*CustomKind
 }
 
 // New returns an instance of XDG that is used to grab files for application use
@@ -46,57 +114,131 @@ func New(vendor, application string) *XDG {
 
 // DataHome returns the location that should be used for user specific data files for this specific application
 func (x *XDG) DataHome() string {
-	return filepath.Join(DataHome(), x.Vendor, x.Application)
+	return x.home(Data)
 }
 
 // DataDirs returns a list of locations that should be used for system wide data files for this specific application
 func (x *XDG) DataDirs() []string {
-	dataDirs := DataDirs()
-	for i, dir := range dataDirs {
-		dataDirs[i] = filepath.Join(dir, x.Vendor, x.Application)
-	}
-	return dataDirs
+	return x.dirs(Data)
 }
 
 // ConfigHome returns the location that should be used for user specific config files for this specific application
 func (x *XDG) ConfigHome() string {
-	return filepath.Join(ConfigHome(), x.Vendor, x.Application)
+	return x.home(Config)
 }
 
 // ConfigDirs returns a list of locations that should be used for system wide config files for this specific application
 func (x *XDG) ConfigDirs() []string {
-	configDirs := ConfigDirs()
-	for i, dir := range configDirs {
-		configDirs[i] = filepath.Join(dir, x.Vendor, x.Application)
-	}
-	return configDirs
+	return x.dirs(Config)
 }
 
 // CacheHome returns the location that should be used for application cache files for this specific application
 func (x *XDG) CacheHome() string {
-	return filepath.Join(CacheHome(), x.Vendor, x.Application)
+	return x.home(Cache)
+}
+
+// StateHome returns the location that should be used for user specific state files for this specific application
+func (x *XDG) StateHome() string {
+	return x.home(State)
+}
+
+func (x *XDG) home(kind Kind) string {
+	if dir, _ := x.override(kind); dir != "" {
+		return dir
+	}
+	if kind == Runtime && x.System {
+		return filepath.Join(systemRuntimeDir(runtime.GOOS), x.name(kind))
+	}
+	if root, _ := x.portableRoot(); root != "" {
+		return filepath.Join(root, kind.String())
+	}
+	base := home(kind)
+	if base == "" {
+		return ""
+	}
+	return filepath.Join(base, x.name(kind))
+}
+
+// override returns the value of the application's environment variable for kind and the name of that variable.
+func (x *XDG) override(kind Kind) (string, string) {
+	if x.EnvPrefix == "" {
+		return "", ""
+	}
+	env := strings.TrimSuffix(x.EnvPrefix, "_") + envSuffix(kind)
+	return os.Getenv(env), env
+}
+
+// envSuffix returns the end of the environment variables naming the home directory of kind, such as _CONFIG_HOME
+func envSuffix(kind Kind) string {
+	if kind == Runtime {
+		return "_RUNTIME_DIR"
+	}
+	return "_" + strings.ToUpper(kind.String()) + "_HOME"
+}
+
+// SearchDirs returns the locations searched for files of kind for this specific application.
+// The home directory comes first followed by the system wide directories, without duplicates.
+func (x *XDG) SearchDirs(kind Kind) []string {
+	return cleanDirs(append([]string{x.home(kind)}, x.dirs(kind)...))
+}
+
+func (x *XDG) dirs(kind Kind) []string {
+	dirs := dirs(kind)
+	for i, dir := range dirs {
+		dirs[i] = filepath.Join(dir, x.name(kind))
+	}
+	return dirs
+}
+
+// Query looks for the given filename in XDG paths for files of kind.
+// Returns an empty string if one was not found.
+// When SafeNames is set, an *UnsafeNameError is returned for a filename that escapes the search directories.
+// When SecureConfig is set, config files other users can change are skipped.
+// ErrUnsupported is returned when the platform has no directories for kind.
+func (x *XDG) Query(kind Kind, filename string) (string, error) {
+	dirs := x.SearchDirs(kind)
+	if len(dirs) == 0 {
+		return "", ErrUnsupported
+	}
+	return x.lookup(kind, filename, dirs)
+}
+
+// QueryAll returns every path of the given filename in XDG paths for files of kind, the most important first.
+// It is used to merge files found in several directories. Like Query, it honors SafeNames and SecureConfig.
+func (x *XDG) QueryAll(kind Kind, filename string) ([]string, error) {
+	dirs := x.SearchDirs(kind)
+	if len(dirs) == 0 {
+		return nil, ErrUnsupported
+	}
+	return x.lookupAll(kind, filename, dirs)
 }
 
 // QueryData looks for the given filename in XDG paths for data files.
 // Returns an empty string if one was not found.
 func (x *XDG) QueryData(filename string) string {
-	dirs := x.DataDirs()
-	dirs = append([]string{x.DataHome()}, dirs...)
-	return returnExist(filename, dirs)
+	path, _ := x.Query(Data, filename)
+	return path
 }
 
 // QueryConfig looks for the given filename in XDG paths for config files.
 // Returns an empty string if one was not found.
 func (x *XDG) QueryConfig(filename string) string {
-	dirs := x.ConfigDirs()
-	dirs = append([]string{x.ConfigHome()}, dirs...)
-	return returnExist(filename, dirs)
+	path, _ := x.Query(Config, filename)
+	return path
 }
 
 // QueryCache looks for the given filename in XDG paths for cache files.
 // Returns an empty string if one was not found.
 func (x *XDG) QueryCache(filename string) string {
-	return returnExist(filename, []string{x.CacheHome()})
+	path, _ := x.Query(Cache, filename)
+	return path
+}
+
+// QueryState looks for the given filename in XDG paths for state files.
+// Returns an empty string if one was not found.
+func (x *XDG) QueryState(filename string) string {
+	path, _ := x.Query(State, filename)
+	return path
 }
 
 func returnExist(filename string, dirs []string) string {
@@ -109,6 +251,62 @@ func returnExist(filename string, dirs []string) string {
 	return ""
 }
 
+// lookup returns the first path of filename in dirs the Query methods accept for files of kind
+func (x *XDG) lookup(kind Kind, filename string, dirs []string) (string, error) {
+	if !x.SecureConfig || kind != Config {
+		if x.SafeNames {
+			return returnSafeExist(filename, dirs)
+		}
+		return returnExist(filename, dirs), nil
+	}
+	for _, dir := range dirs {
+		path := returnExist(filename, []string{dir})
+		if x.SafeNames {
+			var err error
+			if path, err = returnSafeExist(filename, []string{dir}); err != nil {
+				return "", err
+			}
+		}
+		if path != "" && secure(dir, path) {
+			return path, nil
+		}
+	}
+	return "", nil
+}
+
+// lookupAll returns every path of filename in dirs the Query methods accept for files of kind
+func (x *XDG) lookupAll(kind Kind, filename string, dirs []string) ([]string, error) {
+	var paths []string
+	for _, dir := range dirs {
+		path, err := x.lookup(kind, filename, []string{dir})
+		if err != nil {
+			return nil, err
+		}
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// cleanDirs cleans each directory and drops empty and repeated entries, keeping the first occurrence
+func cleanDirs(dirs []string) []string {
+	cleaned := make([]string, 0, len(dirs))
+	seen := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		cleaned = append(cleaned, dir)
+	}
+	return cleaned
+}
+
 // DataHome returns the location that should be used for user specific data files
 func DataHome() string {
 	dataHome := os.Getenv("XDG_DATA_HOME")
@@ -123,10 +321,10 @@ func DataDirs() []string {
 	var dataDirs []string
 	dataDirsStr := os.Getenv("XDG_DATA_DIRS")
 	if dataDirsStr != "" {
-		dataDirs = strings.Split(dataDirsStr, string(os.PathListSeparator))
+		dataDirs = cleanDirs(strings.Split(dataDirsStr, string(os.PathListSeparator)))
 	}
 	if len(dataDirs) == 0 {
-		dataDirs = defaulter.defaultDataDirs()
+		dataDirs = cleanDirs(defaulter.defaultDataDirs())
 	}
 	return dataDirs
 }
@@ -145,10 +343,10 @@ func ConfigDirs() []string {
 	var configDirs []string
 	configDirsStr := os.Getenv("XDG_CONFIG_DIRS")
 	if configDirsStr != "" {
-		configDirs = strings.Split(configDirsStr, string(os.PathListSeparator))
+		configDirs = cleanDirs(strings.Split(configDirsStr, string(os.PathListSeparator)))
 	}
 	if len(configDirs) == 0 {
-		configDirs = defaulter.defaultConfigDirs()
+		configDirs = cleanDirs(defaulter.defaultConfigDirs())
 	}
 	return configDirs
 }
@@ -161,3 +359,53 @@ func CacheHome() string {
 	}
 	return cacheHome
 }
+
+// StateHome returns the location that should be used for user specific state files
+func StateHome() string {
+	stateHome := os.Getenv("XDG_STATE_HOME")
+	if stateHome == "" {
+		stateHome = defaulter.defaultStateHome()
+	}
+	return stateHome
+}
+
+// BinHome returns the location that should be used for user specific executable files
+func BinHome() string {
+	binHome := os.Getenv("XDG_BIN_HOME")
+	if binHome == "" {
+		binHome = defaulter.defaultBinHome()
+	}
+	return binHome
+}
+
+func home(kind Kind) string {
+	switch kind {
+	case Data:
+		return DataHome()
+	case Config:
+		return ConfigHome()
+	case Cache:
+		return CacheHome()
+	case State:
+		return StateHome()
+	case Runtime:
+		return RuntimeDir()
+	}
+	return ""
+}
+
+// SearchDirs returns the locations searched for files of kind.
+// The home directory comes first followed by the system wide directories, without duplicates.
+func SearchDirs(kind Kind) []string {
+	return cleanDirs(append([]string{home(kind)}, dirs(kind)...))
+}
+
+func dirs(kind Kind) []string {
+	switch kind {
+	case Data:
+		return DataDirs()
+	case Config:
+		return ConfigDirs()
+	}
+	return nil
+}
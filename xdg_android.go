@@ -0,0 +1,47 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Android has no system wide XDG directories. Environments such as Termux
+// install their own prefix and export it as PREFIX.
+
+func (o *osDefaulter) defaultDataHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "share")
+}
+
+func (o *osDefaulter) defaultDataDirs() []string {
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		return []string{filepath.Join(prefix, "share")}
+	}
+	return nil
+}
+
+func (o *osDefaulter) defaultConfigHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".config")
+}
+
+func (o *osDefaulter) defaultConfigDirs() []string {
+	if prefix := os.Getenv("PREFIX"); prefix != "" {
+		return []string{filepath.Join(prefix, "etc", "xdg")}
+	}
+	return nil
+}
+
+func (o *osDefaulter) defaultCacheHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".cache")
+}
+
+func (o *osDefaulter) defaultStateHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state")
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "bin")
+}
@@ -1,4 +1,4 @@
-// +build freebsd openbsd netbsd
+// +build freebsd openbsd netbsd dragonfly
 
 // Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
 // Use of this source code is governed by a BSD-style
@@ -30,3 +30,11 @@ func (o *osDefaulter) defaultConfigDirs() []string {
 func (o *osDefaulter) defaultCacheHome() string {
 	return filepath.Join(os.Getenv("HOME"), ".cache")
 }
+
+func (o *osDefaulter) defaultStateHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "state")
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return filepath.Join(os.Getenv("HOME"), ".local", "bin")
+}
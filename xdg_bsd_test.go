@@ -1,4 +1,4 @@
-// +build freebsd openbsd netbsd
+// +build freebsd openbsd netbsd dragonfly
 
 // Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
 // Use of this source code is governed by a BSD-style
@@ -63,3 +63,25 @@ func TestDefaultCacheHome(t *testing.T) {
 	actual := defaulter.defaultCacheHome()
 	assert.Equal(expected, actual)
 }
+
+func TestDefaultStateHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/.local/state"
+	os.Setenv("HOME", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultStateHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultBinHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/.local/bin"
+	os.Setenv("HOME", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultBinHome()
+	assert.Equal(expected, actual)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// iOS applications are sandboxed to their container, which is HOME, so
+// there are no system wide directories.
+
+func (o *osDefaulter) defaultDataHome() string {
+	return filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
+}
+
+func (o *osDefaulter) defaultDataDirs() []string {
+	return nil
+}
+
+func (o *osDefaulter) defaultConfigHome() string {
+	return filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
+}
+
+func (o *osDefaulter) defaultConfigDirs() []string {
+	return nil
+}
+
+func (o *osDefaulter) defaultCacheHome() string {
+	return filepath.Join(os.Getenv("HOME"), "Library", "Caches")
+}
+
+func (o *osDefaulter) defaultStateHome() string {
+	return filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return ""
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Plan 9 keeps per user files in $home/lib and system wide files in /lib and /sys/lib.
+
+func (o *osDefaulter) defaultDataHome() string {
+	return filepath.Join(os.Getenv("home"), "lib")
+}
+
+func (o *osDefaulter) defaultDataDirs() []string {
+	return []string{"/sys/lib", "/lib"}
+}
+
+func (o *osDefaulter) defaultConfigHome() string {
+	return filepath.Join(os.Getenv("home"), "lib")
+}
+
+func (o *osDefaulter) defaultConfigDirs() []string {
+	return []string{"/lib"}
+}
+
+func (o *osDefaulter) defaultCacheHome() string {
+	return filepath.Join(os.Getenv("home"), "lib", "cache")
+}
+
+func (o *osDefaulter) defaultStateHome() string {
+	return filepath.Join(os.Getenv("home"), "lib", "state")
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return filepath.Join(os.Getenv("home"), "bin", os.Getenv("objtype"))
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultDataHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/lib"
+	os.Setenv("home", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultDataHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultDataDirs(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	expected := []string{"/sys/lib", "/lib"}
+
+	actual := defaulter.defaultDataDirs()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultConfigHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/lib"
+	os.Setenv("home", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultConfigHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultConfigDirs(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	expected := []string{"/lib"}
+
+	actual := defaulter.defaultConfigDirs()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultCacheHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/lib/cache"
+	os.Setenv("home", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultCacheHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultStateHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/lib/state"
+	os.Setenv("home", homeDir) // nolint: errcheck
+
+	actual := defaulter.defaultStateHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultBinHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	homeDir := "/some/path"
+	expected := homeDir + "/bin/amd64"
+	os.Setenv("home", homeDir)    // nolint: errcheck
+	os.Setenv("objtype", "amd64") // nolint: errcheck
+
+	actual := defaulter.defaultBinHome()
+	assert.Equal(expected, actual)
+}
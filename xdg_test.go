@@ -39,6 +39,14 @@ func (m *mockDefaulter) defaultCacheHome() string {
 	args := m.Called()
 	return args.String(0)
 }
+func (m *mockDefaulter) defaultStateHome() string {
+	args := m.Called()
+	return args.String(0)
+}
+func (m *mockDefaulter) defaultBinHome() string {
+	args := m.Called()
+	return args.String(0)
+}
 
 const (
 	MDataHome = iota
@@ -46,6 +54,8 @@ const (
 	MConfigHome
 	MConfigDirs
 	MCacheHome
+	MStateHome
+	MBinHome
 )
 
 var getterTestCases = []getterTestCase{
@@ -54,24 +64,35 @@ var getterTestCases = []getterTestCase{
 	{"ConfigHome Without", "defaultConfigHome", filepath.Clean("/some/path"), true, "XDG_CONFIG_HOME", "", MConfigHome, nil, filepath.Clean("/some/path")},
 	{"ConfigDirs Without", "defaultConfigDirs", []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, true, "XDG_CONFIG_DIRS", "", MConfigDirs, nil, []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}},
 	{"CacheHome Without", "defaultCacheHome", filepath.Clean("/some/path"), true, "XDG_CACHE_HOME", "", MCacheHome, nil, filepath.Clean("/some/path")},
+	{"StateHome Without", "defaultStateHome", filepath.Clean("/some/path"), true, "XDG_STATE_HOME", "", MStateHome, nil, filepath.Clean("/some/path")},
+	{"BinHome Without", "defaultBinHome", filepath.Clean("/some/path"), true, "XDG_BIN_HOME", "", MBinHome, nil, filepath.Clean("/some/path")},
 
 	{"DataHome With", "defaultDataHome", filepath.Clean("/wrong/path"), false, "XDG_DATA_HOME", filepath.Clean("/some/path"), MDataHome, nil, filepath.Clean("/some/path")},
 	{"DataDirs With", "defaultDataDirs", []string{filepath.Clean("/wrong/path"), filepath.Clean("/some/other/wrong")}, false, "XDG_DATA_DIRS", strings.Join([]string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, string(os.PathListSeparator)), MDataDirs, nil, []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}},
 	{"ConfigHome With", "defaultConfigHome", filepath.Clean("/wrong/path"), false, "XDG_CONFIG_HOME", filepath.Clean("/some/path"), MConfigHome, nil, filepath.Clean("/some/path")},
 	{"ConfigDirs With", "defaultConfigDirs", []string{filepath.Clean("/wrong/path"), filepath.Clean("/some/other/wrong")}, false, "XDG_CONFIG_DIRS", strings.Join([]string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, string(os.PathListSeparator)), MConfigDirs, nil, []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}},
 	{"CacheHome With", "defaultCacheHome", filepath.Clean("/wrong/path"), false, "XDG_CACHE_HOME", filepath.Clean("/some/path"), MCacheHome, nil, filepath.Clean("/some/path")},
+	{"StateHome With", "defaultStateHome", filepath.Clean("/wrong/path"), false, "XDG_STATE_HOME", filepath.Clean("/some/path"), MStateHome, nil, filepath.Clean("/some/path")},
+	{"BinHome With", "defaultBinHome", filepath.Clean("/wrong/path"), false, "XDG_BIN_HOME", filepath.Clean("/some/path"), MBinHome, nil, filepath.Clean("/some/path")},
+
+	{"DataDirs Default Cleaned", "defaultDataDirs", []string{filepath.Clean("/some/path") + string(filepath.Separator), filepath.Clean("/some/path")}, true, "XDG_DATA_DIRS", "", MDataDirs, nil, []string{filepath.Clean("/some/path")}},
+	{"DataDirs Cleaned", "defaultDataDirs", []string{filepath.Clean("/wrong/path")}, false, "XDG_DATA_DIRS", strings.Join([]string{filepath.Clean("/some/path") + string(filepath.Separator), "", filepath.Clean("/some/other/path"), filepath.Clean("/some/path")}, string(os.PathListSeparator)), MDataDirs, nil, []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}},
+	{"DataDirs Only Empty", "defaultDataDirs", []string{filepath.Clean("/some/path")}, true, "XDG_DATA_DIRS", string(os.PathListSeparator), MDataDirs, nil, []string{filepath.Clean("/some/path")}},
+	{"ConfigDirs Cleaned", "defaultConfigDirs", []string{filepath.Clean("/wrong/path")}, false, "XDG_CONFIG_DIRS", strings.Join([]string{filepath.Clean("/some/path"), filepath.Clean("/some/path") + string(filepath.Separator)}, string(os.PathListSeparator)), MConfigDirs, nil, []string{filepath.Clean("/some/path")}},
 
 	{"DataHome App Without", "defaultDataHome", filepath.Clean("/some/path"), true, "XDG_DATA_HOME", "", MDataHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 	{"DataDirs App Without", "defaultDataDirs", []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, true, "XDG_DATA_DIRS", "", MDataDirs, New("OpenPeeDeeP", "XDG"), []string{filepath.Clean("/some/path/OpenPeeDeeP/XDG"), filepath.Clean("/some/other/path/OpenPeeDeeP/XDG")}},
 	{"ConfigHome App Without", "defaultConfigHome", filepath.Clean("/some/path"), true, "XDG_CONFIG_HOME", "", MConfigHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 	{"ConfigDirs App Without", "defaultConfigDirs", []string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, true, "XDG_CONFIG_DIRS", "", MConfigDirs, New("OpenPeeDeeP", "XDG"), []string{filepath.Clean("/some/path/OpenPeeDeeP/XDG"), filepath.Clean("/some/other/path/OpenPeeDeeP/XDG")}},
 	{"CacheHome App Without", "defaultCacheHome", filepath.Clean("/some/path"), true, "XDG_CACHE_HOME", "", MCacheHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
+	{"StateHome App Without", "defaultStateHome", filepath.Clean("/some/path"), true, "XDG_STATE_HOME", "", MStateHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 
 	{"DataHome App With", "defaultDataHome", filepath.Clean("/wrong/path"), false, "XDG_DATA_HOME", filepath.Clean("/some/path"), MDataHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 	{"DataDirs App With", "defaultDataDirs", []string{filepath.Clean("/wrong/path"), filepath.Clean("/some/other/wrong")}, false, "XDG_DATA_DIRS", strings.Join([]string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, string(os.PathListSeparator)), MDataDirs, New("OpenPeeDeeP", "XDG"), []string{filepath.Clean("/some/path/OpenPeeDeeP/XDG"), filepath.Clean("/some/other/path/OpenPeeDeeP/XDG")}},
 	{"ConfigHome App With", "defaultConfigHome", filepath.Clean("/wrong/path"), false, "XDG_CONFIG_HOME", filepath.Clean("/some/path"), MConfigHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 	{"ConfigDirs App With", "defaultConfigDirs", []string{filepath.Clean("/wrong/path"), filepath.Clean("/some/other/wrong")}, false, "XDG_CONFIG_DIRS", strings.Join([]string{filepath.Clean("/some/path"), filepath.Clean("/some/other/path")}, string(os.PathListSeparator)), MConfigDirs, New("OpenPeeDeeP", "XDG"), []string{filepath.Clean("/some/path/OpenPeeDeeP/XDG"), filepath.Clean("/some/other/path/OpenPeeDeeP/XDG")}},
 	{"CacheHome App With", "defaultCacheHome", filepath.Clean("/wrong/path"), false, "XDG_CACHE_HOME", filepath.Clean("/some/path"), MCacheHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
+	{"StateHome App With", "defaultStateHome", filepath.Clean("/wrong/path"), false, "XDG_STATE_HOME", filepath.Clean("/some/path"), MStateHome, New("OpenPeeDeeP", "XDG"), filepath.Clean("/some/path/OpenPeeDeeP/XDG")},
 }
 
 type getterTestCase struct {
@@ -141,19 +162,43 @@ func computeActual(tc getterTestCase) interface{} {
 		} else {
 			actual = CacheHome()
 		}
+	case MStateHome:
+		if tc.xdgApp != nil {
+			actual = tc.xdgApp.StateHome()
+		} else {
+			actual = StateHome()
+		}
+	case MBinHome:
+		actual = BinHome()
 	}
 	return actual
 }
 
+func TestXDG_SearchDirs(t *testing.T) {
+	assert := assert.New(t)
+	dataDirs := strings.Join([]string{filepath.Clean("/usr/share"), filepath.Clean("/home/user/.local/share"), filepath.Clean("/usr/share") + string(filepath.Separator)}, string(os.PathListSeparator))
+	os.Setenv("XDG_DATA_HOME", filepath.Clean("/home/user/.local/share")) // nolint: errcheck
+	os.Setenv("XDG_DATA_DIRS", dataDirs)                                  // nolint: errcheck
+	os.Setenv("XDG_CACHE_HOME", filepath.Clean("/home/user/.cache"))      // nolint: errcheck
+
+	assert.Equal([]string{filepath.Clean("/home/user/.local/share"), filepath.Clean("/usr/share")}, SearchDirs(Data))
+	assert.Equal([]string{filepath.Clean("/home/user/.cache")}, SearchDirs(Cache))
+
+	x := New("OpenPeeDeeP", "XDG")
+	assert.Equal([]string{filepath.Clean("/home/user/.local/share/OpenPeeDeeP/XDG"), filepath.Clean("/usr/share/OpenPeeDeeP/XDG")}, x.SearchDirs(Data))
+	assert.Equal([]string{filepath.Clean("/home/user/.cache/OpenPeeDeeP/XDG")}, x.SearchDirs(Cache))
+}
+
 const (
 	QData = iota
 	QConfig
 	QCache
+	QState
 )
 
 var (
 	root      = "testingFolder"
-	fileTypes = []string{"data", "config", "cache"}
+	fileTypes = []string{"data", "config", "cache", "state"}
 	fileLoc   = []string{"home", "dirs"}
 )
 
@@ -176,6 +221,9 @@ var queryTestCases = []queryTestCase{
 
 	{"Cache Home", New("OpenPeeDeeP", "XDG"), QCache, "XDG_CACHE_HOME.txt", filepath.Clean("/cache/home/OpenPeeDeeP/XDG/XDG_CACHE_HOME.txt")},
 	{"Cache DNE", New("OpenPeeDeeP", "XDG"), QCache, "XDG_CACHE_DIRS.txt", ""},
+
+	{"State Home", New("OpenPeeDeeP", "XDG"), QState, "XDG_STATE_HOME.txt", filepath.Clean("/state/home/OpenPeeDeeP/XDG/XDG_STATE_HOME.txt")},
+	{"State DNE", New("OpenPeeDeeP", "XDG"), QState, "XDG_STATE_DIRS.txt", ""},
 }
 
 func TestXDG_Query(t *testing.T) {
@@ -199,6 +247,8 @@ func computeQuery(tc queryTestCase) string {
 		actual = tc.xdgApp.QueryCache(tc.filename)
 	case QConfig:
 		actual = tc.xdgApp.QueryConfig(tc.filename)
+	case QState:
+		actual = tc.xdgApp.QueryState(tc.filename)
 	}
 	rootAbs, _ := filepath.Abs(root)
 	actual = strings.Replace(actual, rootAbs, "", 1)
@@ -232,3 +282,17 @@ func standupQueryData(tc queryTestCase) error {
 func teardownQueryData() error {
 	return os.RemoveAll(root)
 }
+
+func TestXDG_QueryUnsupported(t *testing.T) {
+	assert := assert.New(t)
+	mockDef := new(mockDefaulter)
+	mockDef.On("defaultCacheHome").Return("")
+	setDefaulter(mockDef)
+	os.Setenv("XDG_CACHE_HOME", "") // nolint: errcheck
+
+	x := New("OpenPeeDeeP", "XDG")
+	assert.Equal("", x.CacheHome())
+	actual, err := x.Query(Cache, "file.txt")
+	assert.Equal("", actual)
+	assert.Equal(ErrUnsupported, err)
+}
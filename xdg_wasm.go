@@ -0,0 +1,37 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+// WebAssembly has no file system conventions to fall back on. Only the
+// environment variables are used, and the Query methods report ErrUnsupported
+// when they are not set.
+
+func (o *osDefaulter) defaultDataHome() string {
+	return ""
+}
+
+func (o *osDefaulter) defaultDataDirs() []string {
+	return nil
+}
+
+func (o *osDefaulter) defaultConfigHome() string {
+	return ""
+}
+
+func (o *osDefaulter) defaultConfigDirs() []string {
+	return nil
+}
+
+func (o *osDefaulter) defaultCacheHome() string {
+	return ""
+}
+
+func (o *osDefaulter) defaultStateHome() string {
+	return ""
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return ""
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2017, OpenPeeDeeP. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xdg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaults(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	assert.Equal("", defaulter.defaultDataHome())
+	assert.Empty(defaulter.defaultDataDirs())
+	assert.Equal("", defaulter.defaultConfigHome())
+	assert.Empty(defaulter.defaultConfigDirs())
+	assert.Equal("", defaulter.defaultCacheHome())
+	assert.Equal("", defaulter.defaultStateHome())
+	assert.Equal("", defaulter.defaultBinHome())
+}
@@ -4,7 +4,10 @@
 
 package xdg
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+)
 
 func (o *osDefaulter) defaultDataHome() string {
 	return os.Getenv("APPDATA")
@@ -25,3 +28,11 @@ func (o *osDefaulter) defaultConfigDirs() []string {
 func (o *osDefaulter) defaultCacheHome() string {
 	return os.Getenv("LOCALAPPDATA")
 }
+
+func (o *osDefaulter) defaultStateHome() string {
+	return os.Getenv("LOCALAPPDATA")
+}
+
+func (o *osDefaulter) defaultBinHome() string {
+	return filepath.Join(os.Getenv("LOCALAPPDATA"), "Programs")
+}
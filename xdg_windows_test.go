@@ -8,6 +8,7 @@ package xdg
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -67,3 +68,25 @@ func TestDefaultCacheHome(t *testing.T) {
 	actual := defaulter.defaultCacheHome()
 	assert.Equal(expected, actual)
 }
+
+func TestDefaultStateHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	appData := "/some/path"
+	expected := appData
+	os.Setenv("LOCALAPPDATA", appData) // nolint: errcheck
+
+	actual := defaulter.defaultStateHome()
+	assert.Equal(expected, actual)
+}
+
+func TestDefaultBinHome(t *testing.T) {
+	setDefaulter(new(osDefaulter))
+	assert := assert.New(t)
+	appData := "/some/path"
+	expected := filepath.Join(appData, "Programs")
+	os.Setenv("LOCALAPPDATA", appData) // nolint: errcheck
+
+	actual := defaulter.defaultBinHome()
+	assert.Equal(expected, actual)
+}